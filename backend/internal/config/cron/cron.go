@@ -29,6 +29,10 @@ func StartScheduler(db *gorm.DB) (*cron.Cron, cron.EntryID) {
 	}
 
 	jobID, err := scheduler.AddFunc(settings.CronSchedule, func() {
+		if services.IsSchedulerPaused(db) {
+			utils.Logger.Info("⏸️ Scheduled scan skipped: scheduler is paused")
+			return
+		}
 		var reposCount int64
 		if err := db.Model(&models.Repository{}).Count(&reposCount).Error; err != nil {
 			utils.Logger.Errorf("Failed to fetch repositories count: %v", err)
@@ -44,6 +48,17 @@ func StartScheduler(db *gorm.DB) (*cron.Cron, cron.EntryID) {
 		utils.Logger.Fatalf("Failed to schedule cron job: %v", err)
 	}
 
+	if _, err := scheduler.AddFunc("@every 1m", func() {
+		if services.IsSchedulerPaused(db) {
+			return
+		}
+		services.DispatchOutbox(db)
+	}); err != nil {
+		utils.Logger.Fatalf("Failed to schedule notification dispatcher: %v", err)
+	}
+
+	services.StartDigestSchedules(db, scheduler)
+
 	utils.Logger.Infof("Cron job scheduled with ID: %d and schedule: %s", jobID, settings.CronSchedule)
 	utils.Logger.Infof("Cron job timezone: %s", timezone)
 	scheduler.Start()
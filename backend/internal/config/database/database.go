@@ -1,7 +1,11 @@
 package database
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
 	"surveillance/internal/models"
+	"surveillance/internal/services"
 	"surveillance/internal/utils"
 
 	"gorm.io/driver/sqlite"
@@ -20,11 +24,18 @@ func InitDB() *gorm.DB {
 	db.AutoMigrate(
 		&models.Settings{},
 		&models.Repository{},
-		&models.NotificationSettings{},
+		&models.NotificationChannel{},
+		&models.NotificationDelivery{},
+		&models.NotificationOutbox{},
+		&models.ScanRun{},
+		&models.NotificationRule{},
+		&models.OperationalAlert{},
+		&models.RepositoryIgnore{},
 		&models.User{},
 	)
 	ensureDefaultSettings(db)
-	ensureDefaultNotificationSettings(db)
+	initEncryption(db)
+	migrateLegacyNotificationSettings(db)
 	return db
 }
 
@@ -41,15 +52,63 @@ func ensureDefaultSettings(db *gorm.DB) {
 	}
 }
 
-func ensureDefaultNotificationSettings(db *gorm.DB) {
-	var count int64
-	db.Model(&models.NotificationSettings{}).Count(&count)
-	if count == 0 {
-		db.Create(&models.NotificationSettings{
-			WebhookURL:    "",
-			DiscordName:   "Surveillance Bot",
-			DiscordAvatar: "",
-			PingType:      "None",
+// initEncryption derives the encryption key from JWT_SECRET and a salt that is
+// generated once and kept in the settings, so stored secrets stay readable
+// across restarts.
+func initEncryption(db *gorm.DB) {
+	var settings models.Settings
+	if err := db.First(&settings).Error; err != nil {
+		utils.Logger.Fatal("Failed to load settings: ", err)
+	}
+	if settings.EncryptionSalt == "" {
+		salt, err := utils.GenerateRandomSalt(16)
+		if err != nil {
+			utils.Logger.Fatalf("Failed to generate random salt: %v", err)
+		}
+		if err := db.Model(&settings).Update("encryption_salt", salt).Error; err != nil {
+			utils.Logger.Fatal("Failed to store encryption salt: ", err)
+		}
+		settings.EncryptionSalt = salt
+	}
+	utils.SetEncryptionParameters(os.Getenv("JWT_SECRET"), settings.EncryptionSalt)
+}
+
+func migrateLegacyNotificationSettings(db *gorm.DB) {
+	if !db.Migrator().HasTable(&models.NotificationSettings{}) {
+		return
+	}
+	var legacy models.NotificationSettings
+	if err := db.First(&legacy).Error; err == nil && legacy.WebhookURL != "" {
+		config, err := json.Marshal(services.DiscordConfig{
+			WebhookURL:    legacy.WebhookURL,
+			DiscordName:   legacy.DiscordName,
+			DiscordAvatar: legacy.DiscordAvatar,
+			PingType:      legacy.PingType,
 		})
+		if err != nil {
+			utils.Logger.Fatal("Failed to migrate notification settings: ", err)
+		}
+		encryptedConfig, err := services.EncryptChannelConfig(config)
+		if err != nil {
+			utils.Logger.Fatal("Failed to migrate notification settings: ", err)
+		}
+		channel := models.NotificationChannel{
+			Type:    services.ChannelTypeDiscord,
+			Name:    "Discord",
+			Config:  encryptedConfig,
+			Enabled: true,
+		}
+		// Keep the legacy table unless the migrated webhook reads back.
+		if decrypted, err := services.DecryptChannelConfig(channel); err != nil || !bytes.Equal(decrypted, config) {
+			utils.Logger.Error("Failed to verify migrated notification settings, keeping the legacy table: ", err)
+			return
+		}
+		if err := db.Create(&channel).Error; err != nil {
+			utils.Logger.Fatal("Failed to migrate notification settings: ", err)
+		}
+		utils.Logger.Info("Migrated Discord notification settings to a notification channel.")
+	}
+	if err := db.Migrator().DropTable(&models.NotificationSettings{}); err != nil {
+		utils.Logger.Warn("Failed to drop legacy notification settings table: ", err)
 	}
 }
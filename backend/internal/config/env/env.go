@@ -21,12 +21,6 @@ func LoadEnv() {
 		os.Exit(1)
 	}
 
-	salt, err := utils.GenerateRandomSalt(16)
-	if err != nil {
-		utils.Logger.Fatalf("Failed to generate random salt: %v", err)
-		os.Exit(1)
-	}
-
 	timezone := os.Getenv("TIMEZONE")
 	if timezone == "" {
 		timezone = "UTC"
@@ -38,6 +32,4 @@ func LoadEnv() {
 		os.Exit(1)
 	}
 	time.Local = loc
-
-	utils.SetEncryptionParameters(jwtSecret, salt)
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type OperationalAlert struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	Fingerprint      string     `gorm:"uniqueIndex" json:"fingerprint"`
+	Kind             string     `json:"kind"`
+	Title            string     `json:"title"`
+	Message          string     `json:"message"`
+	Active           bool       `json:"active"`
+	FirstSeenAt      time.Time  `json:"firstSeenAt"`
+	LastSeenAt       time.Time  `json:"lastSeenAt"`
+	NotifiedAt       *time.Time `json:"notifiedAt"`
+	NotifiedChannels []uint     `gorm:"serializer:json" json:"notifiedChannels"`
+	ResolvedAt       *time.Time `json:"resolvedAt"`
+}
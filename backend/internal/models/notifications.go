@@ -1,6 +1,11 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type NotificationSettings struct {
 	gorm.Model
@@ -9,3 +14,85 @@ type NotificationSettings struct {
 	DiscordAvatar string `json:"discordAvatar"`
 	PingType      string `json:"pingType"`
 }
+
+type MessageTemplate struct {
+	Title  string `json:"title" yaml:"title,omitempty"`
+	Body   string `json:"body" yaml:"body,omitempty"`
+	Footer string `json:"footer" yaml:"footer,omitempty"`
+}
+
+type QuietHours struct {
+	Enabled        bool   `json:"enabled" yaml:"enabled"`
+	Days           []int  `gorm:"serializer:json" json:"days" yaml:"days,flow,omitempty"`
+	Start          string `json:"start" yaml:"start,omitempty"`
+	End            string `json:"end" yaml:"end,omitempty"`
+	BypassCritical bool   `json:"bypassCritical" yaml:"bypassCritical,omitempty"`
+}
+
+type NotificationChannel struct {
+	gorm.Model
+	Type           string          `gorm:"not null" json:"type"`
+	Name           string          `gorm:"not null" json:"name"`
+	Config         string          `json:"-"`
+	Enabled        bool            `json:"enabled"`
+	Templates      MessageTemplate `gorm:"embedded;embeddedPrefix:template_" json:"templates"`
+	DeliveryMode   string          `gorm:"default:immediate" json:"deliveryMode"`
+	DigestSchedule string          `json:"digestSchedule"`
+	LastDigestAt   *time.Time      `json:"lastDigestAt"`
+	QuietHours     QuietHours      `gorm:"embedded;embeddedPrefix:quiet_" json:"quietHours"`
+
+	OperationalAlerts bool `json:"operationalAlerts"`
+}
+
+type NotificationDelivery struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	ChannelID    uint            `gorm:"index" json:"channelId"`
+	ChannelName  string          `json:"channelName"`
+	ScanRunID    uint            `gorm:"index" json:"scanRunId"`
+	OutboxIDs    []uint          `gorm:"serializer:json" json:"outboxIds"`
+	Attempt      int             `json:"attempt"`
+	StatusCode   int             `json:"statusCode"`
+	Success      bool            `gorm:"index" json:"success"`
+	Error        string          `json:"error"`
+	DurationMs   int64           `json:"durationMs"`
+	Payload      string          `json:"payload"`
+	Notification json.RawMessage `json:"notification"`
+	CreatedAt    time.Time       `gorm:"index" json:"createdAt"`
+}
+
+type NotificationOutbox struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ChannelID     uint       `gorm:"index" json:"channelId"`
+	RepositoryID  uint       `gorm:"index" json:"repositoryId"`
+	ScanRunID     uint       `json:"scanRunId"`
+	Mention       string     `json:"mention"`
+	Routed        bool       `json:"routed"`
+	HeldUntil     *time.Time `json:"heldUntil"`
+	Version       string     `json:"version"`
+	ScanType      string     `json:"scanType"`
+	Update        string     `json:"update"`
+	Status        string     `gorm:"index" json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"nextAttemptAt"`
+	LastError     string     `json:"lastError"`
+	SentMessages  []string   `gorm:"serializer:json" json:"sentMessages"`
+	DeliveredAt   *time.Time `json:"deliveredAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type NotificationRule struct {
+	gorm.Model
+	Name              string `json:"name"`
+	Position          int    `json:"position"`
+	Enabled           bool   `json:"enabled"`
+	RepositoryPattern string `json:"repositoryPattern"`
+	Group             string `json:"group"`
+	Tag               string `json:"tag"`
+	Provider          string `json:"provider"`
+	MinSeverity       string `json:"minSeverity"`
+	SecurityOnly      bool   `json:"securityOnly"`
+	ChannelIDs        []uint `gorm:"serializer:json" json:"channelIds"`
+	Mention           string `json:"mention"`
+	StopProcessing    bool   `json:"stopProcessing"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Repository struct {
 	ID              uint   `gorm:"primaryKey"`
 	Name            string `gorm:"not null"`
@@ -11,4 +13,19 @@ type Repository struct {
 	PublishedAt     string
 	LastScan        string
 	NotifiedVersion string
+	SilencedRelease string
+	Group           string
+	Tags            []string `gorm:"serializer:json"`
+	Provider        string   `gorm:"default:github"`
+	Mention         string
+
+	ConsecutiveFailures int
+	LastError           string
+
+	CooldownDays              *int
+	PendingRelease            string
+	PendingReleasePublishedAt *time.Time
+
+	VersionConstraint string
+	UpstreamLatest    string
 }
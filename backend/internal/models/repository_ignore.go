@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type RepositoryIgnore struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	RepositoryID uint       `gorm:"index" json:"repositoryId"`
+	Type         string     `json:"type"`
+	Value        string     `json:"value"`
+	Until        *time.Time `json:"until"`
+	Reason       string     `json:"reason"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
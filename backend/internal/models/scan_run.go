@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type ScanRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ScanType     string     `json:"scanType"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error"`
+	UpdatesFound int        `json:"updatesFound"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt"`
+}
@@ -4,8 +4,13 @@ import "gorm.io/gorm"
 
 type Settings struct {
 	gorm.Model
-	GitHubAPIKey string
-	CronSchedule string
-	Theme        string
-	LastScan     string
+	GitHubAPIKey    string
+	CronSchedule    string
+	Theme           string
+	LastScan        string
+	SchedulerPaused bool
+	EncryptionSalt  string
+
+	FailureAlertThreshold int `gorm:"default:3"`
+	ReleaseCooldownDays   int
 }
@@ -1,66 +1,426 @@
 package notifications
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"surveillance/internal/models"
 	"surveillance/internal/services"
+	"surveillance/internal/utils"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
+type channelInput struct {
+	Type      string                  `json:"type"`
+	Name      string                  `json:"name"`
+	Enabled   *bool                   `json:"enabled"`
+	Config    json.RawMessage         `json:"config"`
+	Templates *models.MessageTemplate `json:"templates"`
+
+	DeliveryMode   *string            `json:"deliveryMode"`
+	DigestSchedule *string            `json:"digestSchedule"`
+	QuietHours     *models.QuietHours `json:"quietHours"`
+
+	OperationalAlerts *bool `json:"operationalAlerts"`
+}
+
+type channelResponse struct {
+	models.NotificationChannel
+	Config json.RawMessage `json:"config"`
+}
+
+func toChannelResponse(channel models.NotificationChannel) channelResponse {
+	config, err := services.DecryptChannelConfig(channel)
+	if err != nil {
+		utils.Logger.Warn("Decryption failed: ", err)
+		config = []byte("{}")
+	}
+	return channelResponse{NotificationChannel: channel, Config: services.RedactChannelConfig(config)}
+}
+
+func applyChannelInput(channel *models.NotificationChannel, input channelInput) (int, string) {
+	input.Type = strings.ToLower(strings.TrimSpace(input.Type))
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Type == "" || input.Name == "" {
+		return http.StatusBadRequest, "Channel type and name are required"
+	}
+	if len(input.Config) == 0 {
+		input.Config = []byte("{}")
+	}
+	existingConfig := []byte("{}")
+	if channel.Type == input.Type {
+		config, err := services.DecryptChannelConfig(*channel)
+		if err != nil {
+			utils.Logger.Warn("Decryption failed: ", err)
+		} else {
+			existingConfig = config
+		}
+	}
+	config, err := services.RestoreChannelSecrets(input.Config, existingConfig)
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	input.Config = config
+	templates := channel.Templates
+	if input.Templates != nil {
+		templates = *input.Templates
+	}
+	if _, err := services.BuildNotifier(input.Type, input.Config, templates); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	deliveryMode, digestSchedule := channel.DeliveryMode, channel.DigestSchedule
+	if input.DeliveryMode != nil {
+		deliveryMode = strings.ToLower(strings.TrimSpace(*input.DeliveryMode))
+	}
+	if input.DigestSchedule != nil {
+		digestSchedule = strings.TrimSpace(*input.DigestSchedule)
+	}
+	if deliveryMode == "" {
+		deliveryMode = services.DeliveryModeImmediate
+	}
+	if err := services.ValidateDeliveryMode(deliveryMode, digestSchedule); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	quietHours := channel.QuietHours
+	if input.QuietHours != nil {
+		quietHours = *input.QuietHours
+	}
+	if err := services.ValidateQuietHours(quietHours); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	encryptedConfig, err := services.EncryptChannelConfig(input.Config)
+	if err != nil {
+		utils.Logger.Error("Encryption failed: ", err)
+		return http.StatusInternalServerError, "Failed to encrypt channel config"
+	}
+	channel.Type = input.Type
+	channel.Name = input.Name
+	channel.Config = encryptedConfig
+	channel.Templates = templates
+	channel.DeliveryMode = deliveryMode
+	channel.DigestSchedule = digestSchedule
+	channel.QuietHours = quietHours
+	if input.Enabled != nil {
+		channel.Enabled = *input.Enabled
+	}
+	if input.OperationalAlerts != nil {
+		channel.OperationalAlerts = *input.OperationalAlerts
+	}
+	return 0, ""
+}
+
+type ruleInput struct {
+	Name              string `json:"name"`
+	Position          int    `json:"position"`
+	Enabled           *bool  `json:"enabled"`
+	RepositoryPattern string `json:"repositoryPattern"`
+	Group             string `json:"group"`
+	Tag               string `json:"tag"`
+	Provider          string `json:"provider"`
+	MinSeverity       string `json:"minSeverity"`
+	SecurityOnly      bool   `json:"securityOnly"`
+	ChannelIDs        []uint `json:"channelIds"`
+	Mention           string `json:"mention"`
+	StopProcessing    bool   `json:"stopProcessing"`
+}
+
+func applyRuleInput(db *gorm.DB, rule *models.NotificationRule, input ruleInput) error {
+	rule.Name = strings.TrimSpace(input.Name)
+	rule.Position = input.Position
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	rule.RepositoryPattern = strings.TrimSpace(input.RepositoryPattern)
+	rule.Group = strings.TrimSpace(input.Group)
+	rule.Tag = strings.TrimSpace(input.Tag)
+	rule.Provider = strings.ToLower(strings.TrimSpace(input.Provider))
+	rule.MinSeverity = strings.ToLower(strings.TrimSpace(input.MinSeverity))
+	rule.SecurityOnly = input.SecurityOnly
+	rule.ChannelIDs = input.ChannelIDs
+	rule.Mention = strings.TrimSpace(input.Mention)
+	rule.StopProcessing = input.StopProcessing
+	return services.ValidateNotificationRule(db, *rule)
+}
+
+func filterDeliveries(c echo.Context, query *gorm.DB) (*gorm.DB, string) {
+	if channelID := c.QueryParam("channelId"); channelID != "" {
+		id, err := strconv.ParseUint(channelID, 10, 64)
+		if err != nil {
+			return nil, "Invalid channelId"
+		}
+		query = query.Where("channel_id = ?", id)
+	}
+	if scanRunID := c.QueryParam("scanRunId"); scanRunID != "" {
+		id, err := strconv.ParseUint(scanRunID, 10, 64)
+		if err != nil {
+			return nil, "Invalid scanRunId"
+		}
+		query = query.Where("scan_run_id = ?", id)
+	}
+	switch c.QueryParam("status") {
+	case "":
+	case "success":
+		query = query.Where("success = ?", true)
+	case "failed":
+		query = query.Where("success = ?", false)
+	default:
+		return nil, "status must be success or failed"
+	}
+	for param, clause := range map[string]string{"since": "created_at >= ?", "until": "created_at <= ?"} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, param + " must be an RFC 3339 timestamp"
+		}
+		query = query.Where(clause, t)
+	}
+	return query, ""
+}
+
 func RegisterNotificationRoutes(r *echo.Group, db *gorm.DB) {
-	r.GET("/notifications", func(c echo.Context) error {
-		var settings models.NotificationSettings
-		if err := db.First(&settings).Error; err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve settings"})
+	r.GET("/notification-channels", func(c echo.Context) error {
+		var channels []models.NotificationChannel
+		if err := db.Find(&channels).Error; err != nil {
+			utils.Logger.Error("Error fetching notification channels: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch notification channels"})
+		}
+		response := make([]channelResponse, 0, len(channels))
+		for _, channel := range channels {
+			response = append(response, toChannelResponse(channel))
 		}
-		return c.JSON(http.StatusOK, settings)
+		return c.JSON(http.StatusOK, response)
 	})
 
-	r.POST("/notifications", func(c echo.Context) error {
-		var input models.NotificationSettings
+	r.POST("/notification-channels/preview", func(c echo.Context) error {
+		var input struct {
+			Type      string                 `json:"type"`
+			Templates models.MessageTemplate `json:"templates"`
+		}
 		if err := c.Bind(&input); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 		}
+		preview, err := services.PreviewMessage(strings.ToLower(strings.TrimSpace(input.Type)), input.Templates)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, preview)
+	})
 
-		var settings models.NotificationSettings
-		if err := db.First(&settings).Error; err != nil {
-			settings = models.NotificationSettings{
-				WebhookURL:    input.WebhookURL,
-				DiscordName:   input.DiscordName,
-				DiscordAvatar: input.DiscordAvatar,
-				PingType:      input.PingType,
-			}
-			if err := db.Create(&settings).Error; err != nil {
-				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create settings"})
-			}
-		} else {
-			settings.WebhookURL = input.WebhookURL
-			settings.DiscordName = input.DiscordName
-			settings.DiscordAvatar = input.DiscordAvatar
-			settings.PingType = input.PingType
+	r.GET("/notification-channels/:id", func(c echo.Context) error {
+		var channel models.NotificationChannel
+		if err := db.First(&channel, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification channel not found"})
+		}
+		return c.JSON(http.StatusOK, toChannelResponse(channel))
+	})
 
-			if err := db.Save(&settings).Error; err != nil {
-				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update settings"})
-			}
+	r.POST("/notification-channels", func(c echo.Context) error {
+		var input channelInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+		channel := models.NotificationChannel{Enabled: true}
+		if status, message := applyChannelInput(&channel, input); status != 0 {
+			return c.JSON(status, map[string]string{"error": message})
 		}
-		return c.JSON(http.StatusOK, map[string]string{"message": "Notification settings updated"})
+		if err := db.Create(&channel).Error; err != nil {
+			utils.Logger.Error("Error adding notification channel: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create notification channel"})
+		}
+		if err := services.ScheduleDigest(db, channel); err != nil {
+			utils.Logger.Error("Error scheduling digest: ", err)
+		}
+		utils.Logger.Infof("🔔 Notification channel %s (%s) created", channel.Name, channel.Type)
+		return c.JSON(http.StatusCreated, toChannelResponse(channel))
 	})
 
-	r.POST("/notifications/test", func(c echo.Context) error {
-		var settings models.NotificationSettings
-		if err := db.First(&settings).Error; err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve settings"})
+	r.PUT("/notification-channels/:id", func(c echo.Context) error {
+		var channel models.NotificationChannel
+		if err := db.First(&channel, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification channel not found"})
+		}
+		var input channelInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+		if status, message := applyChannelInput(&channel, input); status != 0 {
+			return c.JSON(status, map[string]string{"error": message})
 		}
-		if settings.WebhookURL == "" {
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Webhook URL is not set"})
+		if err := db.Save(&channel).Error; err != nil {
+			utils.Logger.Error("Error updating notification channel: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update notification channel"})
 		}
+		if err := services.ScheduleDigest(db, channel); err != nil {
+			utils.Logger.Error("Error scheduling digest: ", err)
+		}
+		if err := services.ReleaseHeldNotifications(db, channel.ID); err != nil {
+			utils.Logger.Error("Error releasing held notifications: ", err)
+		}
+		return c.JSON(http.StatusOK, toChannelResponse(channel))
+	})
 
-		if err := services.SendTestDiscordNotification(db); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Test notification failed"})
+	r.DELETE("/notification-channels/:id", func(c echo.Context) error {
+		var channel models.NotificationChannel
+		if err := db.First(&channel, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification channel not found"})
 		}
+		if err := services.DeleteNotificationChannel(db, channel); err != nil {
+			utils.Logger.Error("Error deleting notification channel: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete notification channel"})
+		}
+		services.UnscheduleDigest(channel.ID)
+		utils.Logger.Infof("🗑️ Notification channel %s deleted", channel.Name)
+		return c.JSON(http.StatusOK, map[string]string{"message": "Notification channel deleted"})
+	})
 
+	r.POST("/notification-channels/:id/test", func(c echo.Context) error {
+		var channel models.NotificationChannel
+		if err := db.First(&channel, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification channel not found"})
+		}
+		if err := services.SendTestNotification(db, channel); err != nil {
+			utils.Logger.Errorf("Test notification to %s failed: %v", channel.Name, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Test notification failed: " + err.Error()})
+		}
+		utils.Logger.Infof("Test notification sent to %s.", channel.Name)
 		return c.JSON(http.StatusOK, map[string]string{"message": "Test notification sent"})
 	})
+
+	r.POST("/notification-channels/:id/digest", func(c echo.Context) error {
+		var channel models.NotificationChannel
+		if err := db.First(&channel, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification channel not found"})
+		}
+		if channel.DeliveryMode != services.DeliveryModeDigest {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Channel is not in digest mode"})
+		}
+		count := services.SendDigest(db, channel.ID)
+		return c.JSON(http.StatusOK, map[string]interface{}{"message": "Digest dispatched", "updates": count})
+	})
+
+	r.GET("/notifications/log", func(c echo.Context) error {
+		query, message := filterDeliveries(c, db.Model(&models.NotificationDelivery{}))
+		if message != "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": message})
+		}
+		limit, offset := 50, 0
+		if value, err := strconv.Atoi(c.QueryParam("limit")); err == nil && value > 0 {
+			limit = min(value, 500)
+		}
+		if value, err := strconv.Atoi(c.QueryParam("offset")); err == nil && value > 0 {
+			offset = value
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			utils.Logger.Error("Error counting notification deliveries: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch notification log"})
+		}
+		deliveries := []models.NotificationDelivery{}
+		if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+			utils.Logger.Error("Error fetching notification deliveries: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch notification log"})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"total":   total,
+			"limit":   limit,
+			"offset":  offset,
+			"entries": deliveries,
+		})
+	})
+
+	r.POST("/notifications/log/:id/resend", func(c echo.Context) error {
+		var delivery models.NotificationDelivery
+		if err := db.First(&delivery, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Delivery not found"})
+		}
+		if err := services.ResendDelivery(db, delivery); err != nil {
+			if errors.Is(err, services.ErrDeliveryPending) {
+				return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+			}
+			utils.Logger.Errorf("Resending delivery %d failed: %v", delivery.ID, err)
+			return c.JSON(http.StatusBadGateway, map[string]string{"error": "Resend failed: " + err.Error()})
+		}
+		utils.Logger.Infof("🔁 Delivery %d resent to %s", delivery.ID, delivery.ChannelName)
+		return c.JSON(http.StatusOK, map[string]string{"message": "Notification resent"})
+	})
+
+	r.GET("/notifications/alerts", func(c echo.Context) error {
+		query := db.Order("last_seen_at DESC")
+		if c.QueryParam("active") == "true" {
+			query = query.Where("active = ?", true)
+		}
+		alerts := []models.OperationalAlert{}
+		if err := query.Find(&alerts).Error; err != nil {
+			utils.Logger.Error("Error fetching alerts: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch alerts"})
+		}
+		return c.JSON(http.StatusOK, alerts)
+	})
+
+	r.GET("/notification-rules", func(c echo.Context) error {
+		rules := []models.NotificationRule{}
+		if err := db.Order("position, id").Find(&rules).Error; err != nil {
+			utils.Logger.Error("Error fetching notification rules: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch notification rules"})
+		}
+		return c.JSON(http.StatusOK, rules)
+	})
+
+	r.POST("/notification-rules", func(c echo.Context) error {
+		var input ruleInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+		rule := models.NotificationRule{Enabled: true}
+		if err := applyRuleInput(db, &rule, input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if err := db.Create(&rule).Error; err != nil {
+			utils.Logger.Error("Error adding notification rule: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create notification rule"})
+		}
+		utils.Logger.Infof("🧭 Notification rule %s created", rule.Name)
+		return c.JSON(http.StatusCreated, rule)
+	})
+
+	r.PUT("/notification-rules/:id", func(c echo.Context) error {
+		var rule models.NotificationRule
+		if err := db.First(&rule, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification rule not found"})
+		}
+		var input ruleInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+		if err := applyRuleInput(db, &rule, input); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if err := db.Save(&rule).Error; err != nil {
+			utils.Logger.Error("Error updating notification rule: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update notification rule"})
+		}
+		return c.JSON(http.StatusOK, rule)
+	})
+
+	r.DELETE("/notification-rules/:id", func(c echo.Context) error {
+		var rule models.NotificationRule
+		if err := db.First(&rule, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Notification rule not found"})
+		}
+		if err := db.Delete(&rule).Error; err != nil {
+			utils.Logger.Error("Error deleting notification rule: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete notification rule"})
+		}
+		utils.Logger.Infof("🗑️ Notification rule %s deleted", rule.Name)
+		return c.JSON(http.StatusOK, map[string]string{"message": "Notification rule deleted"})
+	})
 }
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"surveillance/internal/models"
+	"surveillance/internal/services"
+	"surveillance/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestServer(t *testing.T) (*echo.Echo, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.NotificationChannel{}, &models.NotificationOutbox{}, &models.NotificationRule{}); err != nil {
+		t.Fatal(err)
+	}
+	utils.SetEncryptionParameters("test-secret", "test-salt")
+	e := echo.New()
+	RegisterNotificationRoutes(e.Group("/api"), db)
+	return e, db
+}
+
+func request(t *testing.T, e *echo.Echo, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s %s: invalid response %q", method, path, rec.Body.String())
+	}
+	return rec.Code, response
+}
+
+func TestChannelSecretsRoundTrip(t *testing.T) {
+	e, db := newTestServer(t)
+	webhook := "https://discord.com/api/webhooks/123/abc-def"
+
+	status, created := request(t, e, http.MethodPost, "/api/notification-channels",
+		`{"type":"discord","name":"Discord","config":{"webhookUrl":"`+webhook+`","discordName":"Bot"}}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create: status %d, %v", status, created)
+	}
+	config := created["config"].(map[string]interface{})
+	if config["webhookUrl"] != services.RedactedSecret {
+		t.Fatalf("create: webhookUrl = %v, want it redacted", config["webhookUrl"])
+	}
+	if config["discordName"] != "Bot" {
+		t.Fatalf("create: discordName = %v, want Bot", config["discordName"])
+	}
+
+	path := "/api/notification-channels/" + strconv.Itoa(int(created["ID"].(float64)))
+	status, fetched := request(t, e, http.MethodGet, path, "")
+	if status != http.StatusOK {
+		t.Fatalf("get: status %d, %v", status, fetched)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"type": "discord", "name": "Renamed", "config": fetched["config"]})
+	status, updated := request(t, e, http.MethodPut, path, string(body))
+	if status != http.StatusOK {
+		t.Fatalf("update: status %d, %v", status, updated)
+	}
+
+	var channel models.NotificationChannel
+	if err := db.First(&channel).Error; err != nil {
+		t.Fatal(err)
+	}
+	stored, err := services.DecryptChannelConfig(channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var storedConfig map[string]interface{}
+	if err := json.Unmarshal(stored, &storedConfig); err != nil {
+		t.Fatal(err)
+	}
+	if storedConfig["webhookUrl"] != webhook {
+		t.Errorf("stored webhookUrl = %v, want %s", storedConfig["webhookUrl"], webhook)
+	}
+	if channel.Name != "Renamed" {
+		t.Errorf("stored name = %s, want Renamed", channel.Name)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"type": "slack", "name": "Slack", "config": map[string]string{"webhookUrl": services.RedactedSecret}})
+	if status, _ := request(t, e, http.MethodPut, path, string(body)); status != http.StatusBadRequest {
+		t.Errorf("changing the type with a redacted secret: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestDeleteChannelCleansUp(t *testing.T) {
+	e, db := newTestServer(t)
+	channels := []models.NotificationChannel{{Name: "A", Type: "discord", Enabled: true}, {Name: "B", Type: "discord", Enabled: true}}
+	if err := db.Create(&channels).Error; err != nil {
+		t.Fatal(err)
+	}
+	shared := models.NotificationRule{Name: "shared", Enabled: true, ChannelIDs: []uint{channels[0].ID, channels[1].ID}}
+	only := models.NotificationRule{Name: "only", Enabled: true, ChannelIDs: []uint{channels[0].ID}}
+	if err := db.Create(&[]*models.NotificationRule{&shared, &only}).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries := []models.NotificationOutbox{
+		{ChannelID: channels[0].ID, Status: services.OutboxStatusPending},
+		{ChannelID: channels[0].ID, Status: services.OutboxStatusDelivered},
+		{ChannelID: channels[1].ID, Status: services.OutboxStatusPending},
+	}
+	if err := db.Create(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/api/notification-channels/" + strconv.Itoa(int(channels[0].ID))
+	if status, response := request(t, e, http.MethodDelete, path, ""); status != http.StatusOK {
+		t.Fatalf("delete: status %d, %v", status, response)
+	}
+
+	var statuses []string
+	db.Model(&models.NotificationOutbox{}).Order("id").Pluck("status", &statuses)
+	want := []string{services.OutboxStatusSuperseded, services.OutboxStatusDelivered, services.OutboxStatusPending}
+	if strings.Join(statuses, ",") != strings.Join(want, ",") {
+		t.Errorf("outbox statuses = %v, want %v", statuses, want)
+	}
+	db.First(&shared, shared.ID)
+	if len(shared.ChannelIDs) != 1 || shared.ChannelIDs[0] != channels[1].ID || !shared.Enabled {
+		t.Errorf("shared rule = %v (enabled %v), want only channel %d and still enabled", shared.ChannelIDs, shared.Enabled, channels[1].ID)
+	}
+	db.First(&only, only.ID)
+	if len(only.ChannelIDs) != 0 || only.Enabled {
+		t.Errorf("only rule = %v (enabled %v), want no channels and disabled", only.ChannelIDs, only.Enabled)
+	}
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"surveillance/internal/models"
+	"surveillance/internal/services"
+	"surveillance/internal/utils"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	importBatchSize     = 100
+	githubImportWorkers = 8
+)
+
+type githubImportEntry struct {
+	Repository    string   `json:"repository"`
+	URL           string   `json:"url"`
+	Description   string   `json:"description,omitempty"`
+	Archived      bool     `json:"archived,omitempty"`
+	Fork          bool     `json:"fork,omitempty"`
+	Topics        []string `json:"topics,omitempty"`
+	Status        string   `json:"status"`
+	LatestRelease string   `json:"latestRelease,omitempty"`
+	RepositoryID  uint     `json:"repositoryId,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// registerGitHubImportRoutes imports the repositories of a GitHub
+// organization, user or starred list. Like the manifest import it only
+// previews unless dryRun is false, and fetches releases for at most
+// maxImportRepositories new repositories per call.
+func registerGitHubImportRoutes(e *echo.Group, db *gorm.DB) {
+	e.POST("/repositories/import/github", func(c echo.Context) error {
+		var payload struct {
+			Source              string   `json:"source"`
+			Account             string   `json:"account"`
+			IncludeArchived     bool     `json:"includeArchived"`
+			IncludeForks        bool     `json:"includeForks"`
+			Topics              []string `json:"topics"`
+			SkipWithoutReleases bool     `json:"skipWithoutReleases"`
+			DryRun              *bool    `json:"dryRun"`
+			Group               string   `json:"group"`
+			Tags                []string `json:"tags"`
+			Repositories        []string `json:"repositories"`
+		}
+		if err := c.Bind(&payload); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+		}
+		dryRun := payload.DryRun == nil || *payload.DryRun
+		filter := services.GitHubRepositoryFilter{IncludeArchived: payload.IncludeArchived, IncludeForks: payload.IncludeForks}
+		for _, topic := range payload.Topics {
+			if topic = strings.ToLower(strings.TrimSpace(topic)); topic != "" {
+				filter.Topics = append(filter.Topics, topic)
+			}
+		}
+
+		githubToken := utils.GetGitHubToken(db)
+		listed, err := services.ListGitHubRepositories(strings.ToLower(strings.TrimSpace(payload.Source)), strings.TrimSpace(payload.Account), githubToken)
+		if err != nil {
+			var apiErr *services.GitHubError
+			if errors.As(err, &apiErr) {
+				if apiErr.StatusCode == http.StatusNotFound {
+					return c.JSON(http.StatusNotFound, map[string]string{"error": "GitHub account " + payload.Account + " not found"})
+				}
+				utils.Logger.Warnf("Failed to list GitHub repositories of %s: %v", payload.Account, err)
+				return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+			}
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+
+		known, err := services.KnownRepositories(db)
+		if err != nil {
+			utils.Logger.Error("Error fetching repositories: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch repositories"})
+		}
+		selected := map[string]bool{}
+		for _, repository := range payload.Repositories {
+			selected[strings.ToLower(strings.TrimSpace(repository))] = true
+		}
+
+		entries := []githubImportEntry{}
+		filtered := 0
+		for _, listedRepo := range listed {
+			if !filter.Matches(listedRepo) {
+				filtered++
+				continue
+			}
+			entry := githubImportEntry{
+				Repository:  listedRepo.FullName,
+				URL:         listedRepo.HTMLURL,
+				Description: listedRepo.Description,
+				Archived:    listedRepo.Archived,
+				Fork:        listedRepo.Fork,
+				Topics:      listedRepo.Topics,
+				Status:      importStatusNew,
+			}
+			if existing := known[strings.ToLower(listedRepo.FullName)]; existing.ID != 0 {
+				entry.Status = importStatusExists
+				entry.RepositoryID = existing.ID
+				entry.LatestRelease = existing.LatestRelease
+			} else if !dryRun && len(selected) > 0 && !selected[strings.ToLower(listedRepo.FullName)] {
+				entry.Status = importStatusSkipped
+			}
+			entries = append(entries, entry)
+		}
+
+		var prepared []models.Repository
+		var preparedEntries []*githubImportEntry
+		if !dryRun || payload.SkipWithoutReleases {
+			var pending []int
+			for i := range entries {
+				if entries[i].Status != importStatusNew {
+					continue
+				}
+				if len(pending) == maxImportRepositories {
+					entries[i].Status, entries[i].Error = importStatusSkipped, importLimitReached
+					continue
+				}
+				pending = append(pending, i)
+			}
+			repos := prepareGitHubRepositories(entries, pending, payload.Group, payload.Tags, payload.SkipWithoutReleases, githubToken)
+			for _, i := range pending {
+				if repos[i] != nil {
+					prepared = append(prepared, *repos[i])
+					preparedEntries = append(preparedEntries, &entries[i])
+				}
+			}
+		}
+
+		if !dryRun && len(prepared) > 0 {
+			if err := db.CreateInBatches(&prepared, importBatchSize).Error; err != nil {
+				utils.Logger.Warnf("Bulk import failed, adding repositories one by one: %v", err)
+				for i := range prepared {
+					prepared[i].ID = 0
+					if err := db.Create(&prepared[i]).Error; err != nil {
+						preparedEntries[i].Status, preparedEntries[i].Error = importStatusFailed, err.Error()
+					}
+				}
+			}
+			added := 0
+			for i, repo := range prepared {
+				if preparedEntries[i].Status == importStatusNew {
+					preparedEntries[i].Status = importStatusAdded
+					preparedEntries[i].RepositoryID = repo.ID
+					added++
+				}
+			}
+			utils.Logger.Infof("📦 Imported %d repositories from GitHub %s %s", added, payload.Source, payload.Account)
+		}
+
+		summary := map[string]int{}
+		for _, entry := range entries {
+			summary[entry.Status]++
+		}
+		if filtered > 0 {
+			summary["filtered"] = filtered
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"dryRun":  dryRun,
+			"summary": summary,
+			"entries": entries,
+		})
+	})
+}
+
+// prepareGitHubRepositories fetches the latest release of the pending entries
+// a few at a time and returns the prepared repositories by entry index. Once
+// GitHub rate limits or rejects the token the remaining entries fail without
+// further requests.
+func prepareGitHubRepositories(entries []githubImportEntry, pending []int, group string, tags []string, skipWithoutReleases bool, githubToken string) map[int]*models.Repository {
+	repos := map[int]*models.Repository{}
+	var mu sync.Mutex
+	var abort error
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range githubImportWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				entry := &entries[i]
+				mu.Lock()
+				aborted := abort
+				mu.Unlock()
+				if aborted != nil {
+					entry.Status, entry.Error = importStatusFailed, aborted.Error()
+					continue
+				}
+				repo := models.Repository{
+					Name:     entry.Repository,
+					URL:      entry.URL,
+					Group:    strings.TrimSpace(group),
+					Tags:     normalizeTags(tags),
+					Provider: services.DefaultProvider,
+				}
+				err := services.PrepareRepository(&repo, githubToken)
+				var apiErr *services.GitHubError
+				switch {
+				case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+					entry.Error = "No releases"
+					entry.Status = importStatusFailed
+					if skipWithoutReleases {
+						entry.Status = importStatusSkipped
+					}
+				case err != nil:
+					if errors.As(err, &apiErr) && (apiErr.RateLimited() || apiErr.Unauthorized()) {
+						mu.Lock()
+						abort = err
+						mu.Unlock()
+					}
+					entry.Status, entry.Error = importStatusFailed, err.Error()
+				default:
+					entry.LatestRelease = repo.LatestRelease
+					mu.Lock()
+					repos[i] = &repo
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return repos
+}
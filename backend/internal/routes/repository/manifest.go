@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"surveillance/internal/models"
+	"surveillance/internal/services"
+	"surveillance/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	maxManifestSize       = 1 << 20
+	maxImportRepositories = 200
+	importLimitReached    = "Import limit reached; run the import again for the rest"
+)
+
+const (
+	importStatusNew        = "new"
+	importStatusAdded      = "added"
+	importStatusExists     = "exists"
+	importStatusDuplicate  = "duplicate"
+	importStatusUnresolved = "unresolved"
+	importStatusSkipped    = "skipped"
+	importStatusFailed     = "failed"
+)
+
+type manifestFile struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+type manifestImportRequest struct {
+	Files        []manifestFile `json:"files"`
+	DryRun       *bool          `json:"dryRun"`
+	Group        string         `json:"group"`
+	Tags         []string       `json:"tags"`
+	Repositories []string       `json:"repositories"`
+}
+
+type manifestImportEntry struct {
+	services.ResolvedDependency
+	Status         string `json:"status"`
+	CurrentVersion string `json:"currentVersion,omitempty"`
+	RepositoryID   uint   `json:"repositoryId,omitempty"`
+}
+
+// registerManifestRoutes imports repositories from dependency manifests.
+// Requests are previews unless dryRun is false; repositories limits the
+// import to a subset of the previewed "owner/name" entries. At most
+// maxImportRepositories repositories are added per call.
+func registerManifestRoutes(e *echo.Group, db *gorm.DB) {
+	e.POST("/repositories/import/manifest", func(c echo.Context) error {
+		payload, err := bindManifestImport(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if len(payload.Files) == 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "No manifest files provided"})
+		}
+		dryRun := payload.DryRun == nil || *payload.DryRun
+
+		var deps []services.ManifestDependency
+		seen := map[string]bool{}
+		for _, file := range payload.Files {
+			parsed, err := services.ParseManifest(file.Filename, []byte(file.Content))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
+			for _, dep := range parsed {
+				key := dep.Ecosystem + ":" + strings.ToLower(dep.Name)
+				if !seen[key] {
+					seen[key] = true
+					deps = append(deps, dep)
+				}
+			}
+		}
+
+		known, err := services.KnownRepositories(db)
+		if err != nil {
+			utils.Logger.Error("Error fetching repositories: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch repositories"})
+		}
+		selected := map[string]bool{}
+		for _, repository := range payload.Repositories {
+			selected[strings.ToLower(strings.TrimSpace(repository))] = true
+		}
+
+		entries := make([]manifestImportEntry, 0, len(deps))
+		claimed := map[string]bool{}
+		for _, resolved := range services.ResolveDependencies(deps) {
+			entry := manifestImportEntry{ResolvedDependency: resolved}
+			key := strings.ToLower(resolved.Repository)
+			switch {
+			case resolved.Repository == "":
+				entry.Status = importStatusUnresolved
+			case known[key].ID != 0:
+				entry.Status = importStatusExists
+				entry.RepositoryID = known[key].ID
+				entry.CurrentVersion = known[key].CurrentVersion
+			case claimed[key]:
+				entry.Status = importStatusDuplicate
+			default:
+				claimed[key] = true
+				entry.Status = importStatusNew
+				entry.CurrentVersion = resolved.Version
+			}
+			entries = append(entries, entry)
+		}
+
+		if !dryRun {
+			githubToken := utils.GetGitHubToken(db)
+			added, attempted := 0, 0
+			for i := range entries {
+				entry := &entries[i]
+				if entry.Status != importStatusNew {
+					continue
+				}
+				if len(selected) > 0 && !selected[strings.ToLower(entry.Repository)] {
+					entry.Status = importStatusSkipped
+					continue
+				}
+				if attempted == maxImportRepositories {
+					entry.Status, entry.Error = importStatusSkipped, importLimitReached
+					continue
+				}
+				attempted++
+				repo := models.Repository{
+					Name:           entry.Repository,
+					URL:            "https://github.com/" + entry.Repository,
+					Group:          strings.TrimSpace(payload.Group),
+					Tags:           normalizeTags(payload.Tags),
+					Provider:       services.DefaultProvider,
+					CurrentVersion: entry.Version,
+				}
+				if err := services.AddRepository(db, &repo, githubToken); err != nil {
+					utils.Logger.Warnf("Failed to import %s: %v", entry.Repository, err)
+					entry.Status = importStatusFailed
+					entry.Error = err.Error()
+					continue
+				}
+				entry.Status = importStatusAdded
+				entry.RepositoryID = repo.ID
+				entry.CurrentVersion = repo.CurrentVersion
+				added++
+			}
+			utils.Logger.Infof("📦 Imported %d repositories from %d manifest files", added, len(payload.Files))
+		}
+
+		summary := map[string]int{}
+		for _, entry := range entries {
+			summary[entry.Status]++
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"dryRun":  dryRun,
+			"summary": summary,
+			"entries": entries,
+		})
+	})
+}
+
+// bindManifestImport accepts either a JSON body or a multipart upload with
+// one or more "files" parts and the options as form fields.
+func bindManifestImport(c echo.Context) (manifestImportRequest, error) {
+	var payload manifestImportRequest
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		if err := c.Bind(&payload); err != nil {
+			return payload, errors.New("Invalid request payload")
+		}
+		for _, file := range payload.Files {
+			if len(file.Content) > maxManifestSize {
+				return payload, errors.New(file.Filename + " is too large")
+			}
+		}
+		return payload, nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return payload, errors.New("Invalid multipart form")
+	}
+	for _, header := range form.File["files"] {
+		if header.Size > maxManifestSize {
+			return payload, errors.New(header.Filename + " is too large")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return payload, errors.New("Failed to read " + header.Filename)
+		}
+		content, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return payload, errors.New("Failed to read " + header.Filename)
+		}
+		payload.Files = append(payload.Files, manifestFile{Filename: header.Filename, Content: string(content)})
+	}
+	if value := c.FormValue("dryRun"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return payload, errors.New("dryRun must be true or false")
+		}
+		payload.DryRun = &dryRun
+	}
+	payload.Group = c.FormValue("group")
+	if tags := c.FormValue("tags"); tags != "" {
+		payload.Tags = strings.Split(tags, ",")
+	}
+	payload.Repositories = form.Value["repositories"]
+	return payload, nil
+}
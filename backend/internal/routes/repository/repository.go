@@ -2,9 +2,12 @@ package repository
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 	"surveillance/internal/models"
 	"surveillance/internal/services"
 	"surveillance/internal/utils"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -13,37 +16,54 @@ import (
 func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 	e.POST("/repositories", func(c echo.Context) error {
 		var payload struct {
-			Name    string `json:"name"`
-			URL     string `json:"url"`
-			Version string `json:"version"`
+			Name     string   `json:"name"`
+			URL      string   `json:"url"`
+			Version  string   `json:"version"`
+			Group    string   `json:"group"`
+			Tags     []string `json:"tags"`
+			Provider string   `json:"provider"`
+			Mention  string   `json:"mention"`
+
+			CooldownDays      *int   `json:"cooldownDays"`
+			VersionConstraint string `json:"versionConstraint"`
 		}
 		if err := c.Bind(&payload); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 		}
+		repo := models.Repository{
+			Name:              payload.Name,
+			URL:               payload.URL,
+			Group:             strings.TrimSpace(payload.Group),
+			Tags:              normalizeTags(payload.Tags),
+			Provider:          strings.ToLower(ifEmpty(strings.TrimSpace(payload.Provider), services.DefaultProvider)),
+			Mention:           strings.TrimSpace(payload.Mention),
+			CooldownDays:      cooldownOverride(payload.CooldownDays),
+			VersionConstraint: strings.TrimSpace(payload.VersionConstraint),
+		}
+		if err := services.ValidateVersionConstraint(repo.VersionConstraint); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+
 		githubToken := utils.GetGitHubToken(db)
 		utils.Logger.Infof("🟣 Initial scan started for %s", payload.Name)
-		releaseVersion, releaseDate, changelog := services.GetLatestReleaseInfo(payload.Name, githubToken)
-		if releaseVersion == "" {
-			utils.Logger.Warnf("Failed to fetch GitHub release info for: %s", payload.Name)
+		tracked, upstream, found, err := services.FetchTrackedRelease(repo, githubToken)
+		if err != nil {
+			utils.Logger.Warnf("Failed to fetch GitHub release info for %s: %v", payload.Name, err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve latest release"})
 		}
-		repo := models.Repository{
-			Name:            payload.Name,
-			URL:             payload.URL,
-			CurrentVersion:  ifEmpty(payload.Version, releaseVersion),
-			LatestRelease:   releaseVersion,
-			LastUpdated:     releaseDate,
-			Changelog:       changelog,
-			NotifiedVersion: releaseVersion,
+		if !found {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "No release matches " + repo.VersionConstraint})
 		}
+		services.BaselineRelease(&repo, tracked, upstream)
+		repo.CurrentVersion = ifEmpty(payload.Version, repo.LatestRelease)
 		if err := db.Create(&repo).Error; err != nil {
 			utils.Logger.Error("Error adding repository: ", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add repository"})
 		}
 
-		utils.Logger.Infof("Latest release for %s: %s - %s", payload.Name, releaseVersion, releaseDate)
+		utils.Logger.Infof("Latest release for %s: %s - %s", payload.Name, repo.LatestRelease, repo.LastUpdated)
 		utils.Logger.Infof("🟣 Initial scan finished")
-		return c.JSON(http.StatusCreated, repo)
+		return c.JSON(http.StatusCreated, toRepositoryResponse(db, repo))
 	})
 
 	e.POST("/repositories/:id/mark-updated", func(c echo.Context) error {
@@ -61,11 +81,14 @@ func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 			utils.Logger.Error("Failed to mark repository as updated: ", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to mark repository as updated"})
 		}
+		if err := services.CancelPendingNotifications(db, repo.ID); err != nil {
+			utils.Logger.Warn("Failed to cancel pending notifications: ", err)
+		}
 		if err := db.First(&repo, id).Error; err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve updated repository"})
 		}
 
-		return c.JSON(http.StatusOK, repo)
+		return c.JSON(http.StatusOK, toRepositoryResponse(db, repo))
 	})
 
 	e.GET("/repositories", func(c echo.Context) error {
@@ -74,7 +97,20 @@ func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 			utils.Logger.Error("Error fetching repositories: ", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch repositories"})
 		}
-		return c.JSON(http.StatusOK, repos)
+		var ignores []models.RepositoryIgnore
+		if err := db.Order("id").Find(&ignores).Error; err != nil {
+			utils.Logger.Error("Error fetching repository ignores: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch repositories"})
+		}
+		byRepository := map[uint][]models.RepositoryIgnore{}
+		for _, ignore := range ignores {
+			byRepository[ignore.RepositoryID] = append(byRepository[ignore.RepositoryID], ignore)
+		}
+		response := make([]repositoryResponse, 0, len(repos))
+		for _, repo := range repos {
+			response = append(response, newRepositoryResponse(repo, byRepository[repo.ID]))
+		}
+		return c.JSON(http.StatusOK, response)
 	})
 
 	e.GET("/repositories/:id/changelog", func(c echo.Context) error {
@@ -95,16 +131,55 @@ func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "Repository not found"})
 		}
 		var payload struct {
-			CurrentVersion string `json:"currentVersion"`
+			CurrentVersion *string   `json:"currentVersion"`
+			Group          *string   `json:"group"`
+			Tags           *[]string `json:"tags"`
+			Provider       *string   `json:"provider"`
+			Mention        *string   `json:"mention"`
+			CooldownDays   *int      `json:"cooldownDays"`
+
+			VersionConstraint *string `json:"versionConstraint"`
 		}
 		if err := c.Bind(&payload); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
 		}
 
-		if payload.CurrentVersion == "" || payload.CurrentVersion == "latest" {
-			repo.CurrentVersion = repo.LatestRelease
-		} else {
-			repo.CurrentVersion = payload.CurrentVersion
+		if payload.Group != nil {
+			repo.Group = strings.TrimSpace(*payload.Group)
+		}
+		if payload.Tags != nil {
+			repo.Tags = normalizeTags(*payload.Tags)
+		}
+		if payload.CooldownDays != nil {
+			repo.CooldownDays = cooldownOverride(payload.CooldownDays)
+		}
+		if payload.Mention != nil {
+			repo.Mention = strings.TrimSpace(*payload.Mention)
+		}
+		if payload.Provider != nil {
+			repo.Provider = strings.ToLower(ifEmpty(strings.TrimSpace(*payload.Provider), services.DefaultProvider))
+		}
+		if payload.VersionConstraint != nil && strings.TrimSpace(*payload.VersionConstraint) != repo.VersionConstraint {
+			repo.VersionConstraint = strings.TrimSpace(*payload.VersionConstraint)
+			if err := services.ValidateVersionConstraint(repo.VersionConstraint); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
+			tracked, upstream, found, err := services.FetchTrackedRelease(repo, utils.GetGitHubToken(db))
+			switch {
+			case err != nil:
+				utils.Logger.Warnf("Failed to re-evaluate %s against %s: %v", repo.Name, repo.VersionConstraint, err)
+				return c.JSON(http.StatusBadGateway, map[string]string{"error": "Failed to fetch releases: " + err.Error()})
+			case !found:
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "No release matches " + repo.VersionConstraint})
+			default:
+				services.BaselineRelease(&repo, tracked, upstream)
+				if err := services.CancelPendingNotifications(db, repo.ID); err != nil {
+					utils.Logger.Warn("Failed to cancel pending notifications: ", err)
+				}
+			}
+		}
+		if payload.CurrentVersion != nil {
+			repo.CurrentVersion = ifEmpty(*payload.CurrentVersion, repo.LatestRelease)
 		}
 		if err := db.Save(&repo).Error; err != nil {
 			utils.Logger.Error("Error updating repository: ", err)
@@ -113,7 +188,7 @@ func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 		if err := db.First(&repo, repoID).Error; err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve updated repository"})
 		}
-		return c.JSON(http.StatusOK, repo)
+		return c.JSON(http.StatusOK, toRepositoryResponse(db, repo))
 	})
 
 	e.DELETE("/repositories/:id", func(c echo.Context) error {
@@ -123,13 +198,79 @@ func RegisterRepositoryRoutes(e *echo.Group, db *gorm.DB) {
 			utils.Logger.Error("Repository not found: ", err)
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "Repository not found"})
 		}
-		if err := db.Delete(&repo).Error; err != nil {
+		if err := services.DeleteRepository(db, repo); err != nil {
 			utils.Logger.Error("Error deleting repository: ", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete repository"})
 		}
 		utils.Logger.Infof("🗑️ Repository %s deleted", repo.Name)
 		return c.JSON(http.StatusOK, map[string]string{"message": "Repository deleted"})
 	})
+
+	e.GET("/repositories/:id/ignores", func(c echo.Context) error {
+		var repo models.Repository
+		if err := db.First(&repo, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Repository not found"})
+		}
+		ignores, err := services.RepositoryIgnores(db, repo.ID)
+		if err != nil {
+			utils.Logger.Error("Error fetching repository ignores: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch ignores"})
+		}
+		return c.JSON(http.StatusOK, ignores)
+	})
+
+	e.POST("/repositories/:id/ignores", func(c echo.Context) error {
+		var repo models.Repository
+		if err := db.First(&repo, c.Param("id")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Repository not found"})
+		}
+		var payload struct {
+			Type   string `json:"type"`
+			Value  string `json:"value"`
+			Until  string `json:"until"`
+			Reason string `json:"reason"`
+		}
+		if err := c.Bind(&payload); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+		}
+		ignore := models.RepositoryIgnore{
+			RepositoryID: repo.ID,
+			Type:         strings.ToLower(strings.TrimSpace(payload.Type)),
+			Value:        strings.TrimSpace(payload.Value),
+			Reason:       strings.TrimSpace(payload.Reason),
+		}
+		if payload.Until != "" {
+			until, err := parseUntil(payload.Until)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "until must be a date (2006-01-02) or RFC 3339 timestamp"})
+			}
+			ignore.Until = &until
+		}
+		if err := services.ValidateIgnore(ignore); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if err := db.Create(&ignore).Error; err != nil {
+			utils.Logger.Error("Error adding repository ignore: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add ignore"})
+		}
+		utils.Logger.Infof("🔕 %s: ignoring %s %s", repo.Name, ignore.Type, ignore.Value)
+		return c.JSON(http.StatusCreated, ignore)
+	})
+
+	e.DELETE("/repositories/:id/ignores/:ignoreId", func(c echo.Context) error {
+		var ignore models.RepositoryIgnore
+		if err := db.Where("repository_id = ?", c.Param("id")).First(&ignore, c.Param("ignoreId")).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Ignore not found"})
+		}
+		if err := db.Delete(&ignore).Error; err != nil {
+			utils.Logger.Error("Error deleting repository ignore: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete ignore"})
+		}
+		return c.JSON(http.StatusOK, map[string]string{"message": "Ignore removed"})
+	})
+
+	registerManifestRoutes(e, db)
+	registerGitHubImportRoutes(e, db)
 }
 
 func ifEmpty(value, fallback string) string {
@@ -138,3 +279,52 @@ func ifEmpty(value, fallback string) string {
 	}
 	return value
 }
+
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// cooldownOverride maps a negative cooldown to "use the global default".
+func cooldownOverride(days *int) *int {
+	if days == nil || *days < 0 {
+		return nil
+	}
+	return days
+}
+
+type repositoryResponse struct {
+	models.Repository
+	UpdateAvailable bool                      `json:"updateAvailable"`
+	IgnoredBy       *models.RepositoryIgnore  `json:"ignoredBy"`
+	Ignores         []models.RepositoryIgnore `json:"ignores"`
+}
+
+func newRepositoryResponse(repo models.Repository, ignores []models.RepositoryIgnore) repositoryResponse {
+	if ignores == nil {
+		ignores = []models.RepositoryIgnore{}
+	}
+	updateAvailable, ignoredBy := services.UpdateAvailable(repo, ignores)
+	return repositoryResponse{Repository: repo, UpdateAvailable: updateAvailable, IgnoredBy: ignoredBy, Ignores: ignores}
+}
+
+func toRepositoryResponse(db *gorm.DB, repo models.Repository) repositoryResponse {
+	ignores, err := services.RepositoryIgnores(db, repo.ID)
+	if err != nil {
+		utils.Logger.Warn("Failed to load repository ignores: ", err)
+	}
+	return newRepositoryResponse(repo, ignores)
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if until, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return until, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -8,8 +8,10 @@ import (
 	"surveillance/internal/routes/notifications"
 	"surveillance/internal/routes/repository"
 	"surveillance/internal/routes/scan"
+	"surveillance/internal/routes/scheduling"
 	"surveillance/internal/routes/settings"
 	"surveillance/internal/routes/validation"
+	"surveillance/internal/routes/watchlist"
 	"surveillance/internal/utils"
 
 	echojwt "github.com/labstack/echo-jwt/v4"
@@ -47,6 +49,8 @@ func RegisterRoutes(e *echo.Echo, db *gorm.DB, scheduler *cron.Cron, jobID *cron
 	settings.RegisterSettingsRoutes(protected, db, scheduler, jobID)
 	notifications.RegisterNotificationRoutes(protected, db)
 	scan.RegisterScanRoutes(protected, db)
+	scheduling.RegisterSchedulerRoutes(protected, db)
+	watchlist.RegisterWatchlistRoutes(protected, db)
 	protected.GET("/validate-key", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]string{"message": "GitHub API key is valid"})
 	})
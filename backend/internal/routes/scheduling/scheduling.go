@@ -0,0 +1,30 @@
+package scheduling
+
+import (
+	"net/http"
+	"surveillance/internal/services"
+	"surveillance/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func RegisterSchedulerRoutes(r *echo.Group, db *gorm.DB) {
+	r.POST("/scheduler/pause", func(c echo.Context) error {
+		if err := services.SetSchedulerPaused(db, true); err != nil {
+			utils.Logger.Error("Failed to pause scheduler: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to pause scheduler"})
+		}
+		utils.Logger.Info("⏸️ Scheduled scanning paused")
+		return c.JSON(http.StatusOK, map[string]string{"message": "Scheduler paused"})
+	})
+
+	r.POST("/scheduler/resume", func(c echo.Context) error {
+		if err := services.SetSchedulerPaused(db, false); err != nil {
+			utils.Logger.Error("Failed to resume scheduler: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resume scheduler"})
+		}
+		utils.Logger.Info("▶️ Scheduled scanning resumed")
+		return c.JSON(http.StatusOK, map[string]string{"message": "Scheduler resumed"})
+	})
+}
@@ -26,10 +26,12 @@ func RegisterSettingsRoutes(e *echo.Group, db *gorm.DB, scheduler *cron.Cron, jo
 				utils.Logger.Warn("Decryption failed: ", err)
 			}
 		}
-		return c.JSON(http.StatusOK, map[string]string{
-			"githubApiKey": decryptedAPIKey,
-			"cronSchedule": settings.CronSchedule,
-			"theme":        settings.Theme,
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"githubApiKey":          decryptedAPIKey,
+			"cronSchedule":          settings.CronSchedule,
+			"theme":                 settings.Theme,
+			"failureAlertThreshold": services.FailureAlertThreshold(db),
+			"releaseCooldownDays":   settings.ReleaseCooldownDays,
 		})
 	})
 	e.POST("/settings", func(c echo.Context) error {
@@ -38,6 +40,9 @@ func RegisterSettingsRoutes(e *echo.Group, db *gorm.DB, scheduler *cron.Cron, jo
 			CronSchedule string `json:"cronSchedule"`
 			GitHubAPIKey string `json:"githubApiKey"`
 			IsReset      bool   `json:"isReset"`
+
+			FailureAlertThreshold *int `json:"failureAlertThreshold"`
+			ReleaseCooldownDays   *int `json:"releaseCooldownDays"`
 		}
 		if err := c.Bind(&input); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
@@ -53,6 +58,10 @@ func RegisterSettingsRoutes(e *echo.Group, db *gorm.DB, scheduler *cron.Cron, jo
 		if settings.CronSchedule != input.CronSchedule {
 			scheduler.Remove(*jobID)
 			newJobID, err := scheduler.AddFunc(input.CronSchedule, func() {
+				if services.IsSchedulerPaused(db) {
+					utils.Logger.Info("⏸️ Scheduled scan skipped: scheduler is paused")
+					return
+				}
 				githubToken := utils.GetGitHubToken(db)
 				if err := services.MonitorRepositories(db, githubToken, "", false); err != nil {
 					utils.Logger.Errorf("Repository scan failed: %v", err)
@@ -88,6 +97,20 @@ func RegisterSettingsRoutes(e *echo.Group, db *gorm.DB, scheduler *cron.Cron, jo
 			settings.Theme = input.Theme
 			settingsUpdated = true
 		}
+		if input.FailureAlertThreshold != nil {
+			if *input.FailureAlertThreshold < 1 {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failure alert threshold must be at least 1"})
+			}
+			settings.FailureAlertThreshold = *input.FailureAlertThreshold
+			settingsUpdated = true
+		}
+		if input.ReleaseCooldownDays != nil {
+			if *input.ReleaseCooldownDays < 0 {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Release cooldown cannot be negative"})
+			}
+			settings.ReleaseCooldownDays = *input.ReleaseCooldownDays
+			settingsUpdated = true
+		}
 		if settingsUpdated {
 			if err := db.Save(&settings).Error; err != nil {
 				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update settings"})
@@ -0,0 +1,116 @@
+package watchlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"surveillance/internal/services"
+	"surveillance/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+const maxImportSize = 5 << 20
+
+func RegisterWatchlistRoutes(r *echo.Group, db *gorm.DB) {
+	r.GET("/export", func(c echo.Context) error {
+		format := strings.ToLower(c.QueryParam("format"))
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "yaml" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json or yaml"})
+		}
+		includeChannels, _ := strconv.ParseBool(c.QueryParam("channels"))
+		includeSecrets, _ := strconv.ParseBool(c.QueryParam("secrets"))
+
+		watchlist, err := services.ExportWatchlist(db, includeChannels, includeSecrets)
+		if err != nil {
+			utils.Logger.Error("Error exporting watchlist: ", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export watchlist"})
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="surveillance-watchlist.`+format+`"`)
+		if format == "yaml" {
+			var body bytes.Buffer
+			encoder := yaml.NewEncoder(&body)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(watchlist); err != nil {
+				utils.Logger.Error("Error encoding watchlist: ", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export watchlist"})
+			}
+			return c.Blob(http.StatusOK, "application/yaml", body.Bytes())
+		}
+		return c.JSONPretty(http.StatusOK, watchlist, "  ")
+	})
+
+	r.POST("/import", func(c echo.Context) error {
+		mode := strings.ToLower(c.QueryParam("mode"))
+		if mode == "" {
+			mode = services.ImportModeMerge
+		}
+		if mode != services.ImportModeMerge && mode != services.ImportModeReplace {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be merge or replace"})
+		}
+		conflicts := strings.ToLower(c.QueryParam("conflicts"))
+		if conflicts != "" && conflicts != "overwrite" && conflicts != "skip" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "conflicts must be overwrite or skip"})
+		}
+		dryRun := true
+		if value := c.QueryParam("dryRun"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "dryRun must be true or false"})
+			}
+		}
+		confirm := false
+		if value := c.QueryParam("confirm"); value != "" {
+			var err error
+			if confirm, err = strconv.ParseBool(value); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "confirm must be true or false"})
+			}
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxImportSize+1))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read request body"})
+		}
+		if len(body) > maxImportSize {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Watchlist is too large"})
+		}
+		watchlist, err := decodeWatchlist(c.Request().Header.Get(echo.HeaderContentType), body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid watchlist: " + err.Error()})
+		}
+
+		report, err := services.ImportWatchlist(db, watchlist, services.ImportOptions{
+			Mode:          mode,
+			DryRun:        dryRun,
+			SkipConflicts: conflicts == "skip",
+			Confirm:       confirm,
+		}, utils.GetGitHubToken(db))
+		if err != nil {
+			utils.Logger.Error("Error importing watchlist: ", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if !dryRun {
+			utils.Logger.Infof("📥 Watchlist imported (%s): %v", mode, report.Summary)
+		}
+		return c.JSON(http.StatusOK, report)
+	})
+}
+
+// decodeWatchlist reads JSON when the request says so or the body looks like
+// a JSON object, and YAML otherwise.
+func decodeWatchlist(contentType string, body []byte) (services.Watchlist, error) {
+	var watchlist services.Watchlist
+	trimmed := strings.TrimSpace(string(body))
+	if strings.Contains(contentType, "json") || strings.HasPrefix(trimmed, "{") {
+		return watchlist, json.Unmarshal(body, &watchlist)
+	}
+	return watchlist, yaml.Unmarshal(body, &watchlist)
+}
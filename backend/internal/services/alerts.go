@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AlertScanFailed        = "scan_failed"
+	AlertRepositoryFailing = "repository_failing"
+	AlertTokenInvalid      = "token_invalid"
+	AlertRateLimited       = "rate_limited"
+
+	defaultFailureAlertThreshold = 3
+)
+
+type Alert struct {
+	Kind     string `json:"kind"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Resolved bool   `json:"resolved"`
+}
+
+// RaiseAlert records an operational problem and notifies the alert channels
+// the first time it is seen. While some channel has not received it yet,
+// raising it again retries only those channels; once every channel has it,
+// or there are none, raising it only refreshes its timestamps.
+func RaiseAlert(db *gorm.DB, fingerprint string, alert Alert) {
+	now := time.Now()
+	var state models.OperationalAlert
+	err := db.Where("fingerprint = ?", fingerprint).First(&state).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Logger.Errorf("Failed to load alert state for %s: %v", fingerprint, err)
+		return
+	}
+	raised := !state.Active
+	if raised {
+		state.FirstSeenAt = now
+		state.ResolvedAt = nil
+		state.NotifiedAt = nil
+		state.NotifiedChannels = nil
+	}
+	state.Fingerprint = fingerprint
+	state.Kind = alert.Kind
+	state.Title = alert.Title
+	state.Message = alert.Message
+	state.Active = true
+	state.LastSeenAt = now
+
+	if raised {
+		utils.Logger.Warnf("🚨 %s: %s", alert.Title, alert.Message)
+	}
+	if state.NotifiedAt == nil {
+		var channels []models.NotificationChannel
+		query := db.Where("enabled = ? AND operational_alerts = ?", true, true)
+		if len(state.NotifiedChannels) > 0 {
+			query = query.Where("id NOT IN ?", state.NotifiedChannels)
+		}
+		if err := query.Find(&channels).Error; err != nil {
+			utils.Logger.Errorf("Failed to load alert channels: %v", err)
+		} else {
+			delivered := notifyAlertChannels(db, channels, alert)
+			state.NotifiedChannels = append(state.NotifiedChannels, delivered...)
+			if len(delivered) == len(channels) {
+				state.NotifiedAt = &now
+			}
+		}
+	}
+	if err := db.Save(&state).Error; err != nil {
+		utils.Logger.Errorf("Failed to save alert state for %s: %v", fingerprint, err)
+	}
+}
+
+// ResolveAlert marks the alert resolved and tells the channels that received
+// it.
+func ResolveAlert(db *gorm.DB, fingerprint, message string) {
+	var state models.OperationalAlert
+	if err := db.Where("fingerprint = ? AND active = ?", fingerprint, true).First(&state).Error; err != nil {
+		return
+	}
+	now := time.Now()
+	if len(state.NotifiedChannels) > 0 {
+		var channels []models.NotificationChannel
+		if err := db.Where("enabled = ? AND id IN ?", true, state.NotifiedChannels).Find(&channels).Error; err != nil {
+			utils.Logger.Errorf("Failed to load alert channels: %v", err)
+		}
+		notifyAlertChannels(db, channels, Alert{Kind: state.Kind, Title: "Resolved: " + state.Title, Message: message, Resolved: true})
+	}
+	state.Active = false
+	state.ResolvedAt = &now
+	state.NotifiedAt = nil
+	state.NotifiedChannels = nil
+	if err := db.Save(&state).Error; err != nil {
+		utils.Logger.Errorf("Failed to save alert state for %s: %v", fingerprint, err)
+	}
+	utils.Logger.Infof("✅ Resolved alert %s", fingerprint)
+}
+
+func ForgetRepositoryAlerts(db *gorm.DB, repositoryID uint) error {
+	return db.Where("fingerprint = ?", repositoryAlertFingerprint(repositoryID)).Delete(&models.OperationalAlert{}).Error
+}
+
+func FailureAlertThreshold(db *gorm.DB) int {
+	var settings models.Settings
+	if err := db.First(&settings).Error; err != nil || settings.FailureAlertThreshold <= 0 {
+		return defaultFailureAlertThreshold
+	}
+	return settings.FailureAlertThreshold
+}
+
+// notifyAlertChannels sends alert to channels and returns the IDs of the
+// ones that received it.
+func notifyAlertChannels(db *gorm.DB, channels []models.NotificationChannel, alert Alert) []uint {
+	notification := Notification{ScanType: "Alert", Time: time.Now(), Alert: &alert}
+	var delivered []uint
+	for _, channel := range channels {
+		notifier, err := newRecordedNotifier(db, channel, deliveryContext{notification: notification})
+		if err == nil {
+			err = notifier.Send(notification)
+		}
+		if err != nil {
+			utils.Logger.Errorf("Failed to send alert to %s: %v", channel.Name, err)
+			continue
+		}
+		delivered = append(delivered, channel.ID)
+	}
+	return delivered
+}
+
+func repositoryAlertFingerprint(repositoryID uint) string {
+	return fmt.Sprintf("%s:%d", AlertRepositoryFailing, repositoryID)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.NotificationChannel{}, &models.NotificationDelivery{}, &models.OperationalAlert{}); err != nil {
+		t.Fatal(err)
+	}
+	utils.SetEncryptionParameters("test-secret", "test-salt")
+	return db
+}
+
+type webhookStub struct {
+	requests atomic.Int32
+	status   atomic.Int32
+	server   *httptest.Server
+}
+
+func newWebhookStub(t *testing.T, status int) *webhookStub {
+	stub := &webhookStub{}
+	stub.status.Store(int32(status))
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.requests.Add(1)
+		w.WriteHeader(int(stub.status.Load()))
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func createWebhookChannel(t *testing.T, db *gorm.DB, name, url string) models.NotificationChannel {
+	t.Helper()
+	config, err := EncryptChannelConfig([]byte(`{"url":"` + url + `","secret":"s"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := models.NotificationChannel{Name: name, Type: ChannelTypeWebhook, Enabled: true, OperationalAlerts: true, Config: config}
+	if err := db.Create(&channel).Error; err != nil {
+		t.Fatal(err)
+	}
+	return channel
+}
+
+func TestRaiseAlertRetriesFailedChannels(t *testing.T) {
+	db := newTestDB(t)
+	working := newWebhookStub(t, http.StatusOK)
+	failing := newWebhookStub(t, http.StatusBadRequest)
+	createWebhookChannel(t, db, "working", working.server.URL)
+	createWebhookChannel(t, db, "failing", failing.server.URL)
+	alert := Alert{Kind: AlertScanFailed, Title: "Scheduled scan failed", Message: "boom"}
+
+	RaiseAlert(db, AlertScanFailed, alert)
+	var state models.OperationalAlert
+	db.First(&state)
+	if state.NotifiedAt != nil || len(state.NotifiedChannels) != 1 {
+		t.Fatalf("after a partial failure: notifiedAt %v, channels %v", state.NotifiedAt, state.NotifiedChannels)
+	}
+
+	failing.status.Store(http.StatusOK)
+	RaiseAlert(db, AlertScanFailed, alert)
+	if working.requests.Load() != 1 || failing.requests.Load() != 2 {
+		t.Fatalf("retry sent %d/%d requests, want 1/2", working.requests.Load(), failing.requests.Load())
+	}
+	db.First(&state)
+	if state.NotifiedAt == nil || len(state.NotifiedChannels) != 2 {
+		t.Fatalf("after the retry: notifiedAt %v, channels %v", state.NotifiedAt, state.NotifiedChannels)
+	}
+
+	RaiseAlert(db, AlertScanFailed, alert)
+	if working.requests.Load() != 1 || failing.requests.Load() != 2 {
+		t.Fatalf("a delivered alert was sent again")
+	}
+
+	ResolveAlert(db, AlertScanFailed, "fixed")
+	if working.requests.Load() != 2 || failing.requests.Load() != 3 {
+		t.Fatalf("resolve sent %d/%d requests, want 2/3", working.requests.Load(), failing.requests.Load())
+	}
+}
+
+func TestRaiseAlertWithoutAlertChannels(t *testing.T) {
+	db := newTestDB(t)
+	RaiseAlert(db, AlertTokenInvalid, Alert{Kind: AlertTokenInvalid, Title: "Invalid token", Message: "401"})
+	var state models.OperationalAlert
+	db.First(&state)
+	if state.NotifiedAt == nil {
+		t.Fatal("an alert without alert channels should not stay pending")
+	}
+}
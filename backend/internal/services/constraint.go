@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"surveillance/internal/models"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	releasesPerPage = 100
+	maxReleasePages = 5
+)
+
+// releaseLists caches the release list of each constrained repository with
+// the ETag of its first page. New releases show up on that page, and GitHub
+// answers an unchanged one with a 304 that does not count against the rate
+// limit, so pinned repositories are not paged through on every scan.
+var releaseLists = struct {
+	sync.Mutex
+	entries map[string]cachedReleaseList
+}{entries: map[string]cachedReleaseList{}}
+
+type cachedReleaseList struct {
+	etag     string
+	releases []GitHubRelease
+}
+
+func ValidateVersionConstraint(constraint string) error {
+	if constraint == "" {
+		return nil
+	}
+	if _, err := semver.NewConstraint(constraint); err != nil {
+		return fmt.Errorf("Invalid version constraint %q: %v", constraint, err)
+	}
+	return nil
+}
+
+// FetchTrackedRelease returns the newest release within the repository's
+// version constraint alongside the upstream latest release. Without a
+// constraint both are the same; found is false when nothing matches.
+func FetchTrackedRelease(repo models.Repository, githubToken string) (GitHubRelease, GitHubRelease, bool, error) {
+	upstream, err := FetchLatestRelease(repo.Name, githubToken)
+	if err != nil {
+		return GitHubRelease{}, upstream, false, err
+	}
+	if repo.VersionConstraint == "" {
+		return upstream, upstream, true, nil
+	}
+	constraint, err := semver.NewConstraint(repo.VersionConstraint)
+	if err != nil {
+		return GitHubRelease{}, upstream, false, err
+	}
+	if version, err := semver.NewVersion(upstream.TagName); err == nil && constraint.Check(version) {
+		return upstream, upstream, true, nil
+	}
+
+	releases, err := FetchReleases(repo.Name, githubToken)
+	if err != nil {
+		return GitHubRelease{}, upstream, false, err
+	}
+	tracked, found := newestMatchingRelease(releases, constraint)
+	return tracked, upstream, found, nil
+}
+
+// BaselineRelease makes tracked the repository's latest known release without
+// announcing it, e.g. when a repository is added or its constraint changes.
+func BaselineRelease(repo *models.Repository, tracked, upstream GitHubRelease) {
+	repo.LatestRelease = tracked.TagName
+	repo.LastUpdated = formatReleaseDate(tracked.PublishedAt)
+	repo.Changelog = tracked.Body
+	repo.NotifiedVersion = tracked.TagName
+	repo.SilencedRelease = ""
+	repo.UpstreamLatest = upstream.TagName
+	repo.PendingRelease = ""
+	repo.PendingReleasePublishedAt = nil
+}
+
+func FetchReleases(repoName, githubToken string) ([]GitHubRelease, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	key := strings.ToLower(repoName)
+	releaseLists.Lock()
+	cached, isCached := releaseLists.entries[key]
+	releaseLists.Unlock()
+
+	var releases []GitHubRelease
+	var etag string
+	for page := 1; page <= maxReleasePages; page++ {
+		url := "https://api.github.com/repos/" + repoName + "/releases?per_page=" + strconv.Itoa(releasesPerPage) + "&page=" + strconv.Itoa(page)
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if githubToken != "" {
+			req.Header.Set("Authorization", "Bearer "+githubToken)
+		}
+		if page == 1 && isCached {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if page == 1 {
+			if resp.StatusCode == http.StatusNotModified && isCached {
+				resp.Body.Close()
+				return cached.releases, nil
+			}
+			etag = resp.Header.Get("ETag")
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, newGitHubError(resp)
+		}
+		var batch []GitHubRelease
+		err = json.NewDecoder(resp.Body).Decode(&batch)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode releases: %w", err)
+		}
+		releases = append(releases, batch...)
+		if len(batch) < releasesPerPage {
+			break
+		}
+	}
+	releaseLists.Lock()
+	if etag != "" {
+		releaseLists.entries[key] = cachedReleaseList{etag: etag, releases: releases}
+	} else {
+		delete(releaseLists.entries, key)
+	}
+	releaseLists.Unlock()
+	return releases, nil
+}
+
+func newestMatchingRelease(releases []GitHubRelease, constraint *semver.Constraints) (GitHubRelease, bool) {
+	var best GitHubRelease
+	var bestVersion *semver.Version
+	for _, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil || !constraint.Check(version) {
+			continue
+		}
+		if bestVersion == nil || version.GreaterThan(bestVersion) {
+			best, bestVersion = release, version
+		}
+	}
+	return best, bestVersion != nil
+}
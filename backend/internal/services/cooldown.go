@@ -0,0 +1,66 @@
+package services
+
+import (
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func DefaultCooldownDays(db *gorm.DB) int {
+	var settings models.Settings
+	if err := db.First(&settings).Error; err != nil {
+		return 0
+	}
+	return settings.ReleaseCooldownDays
+}
+
+func releaseCooldown(repo models.Repository, defaultDays int) time.Duration {
+	days := defaultDays
+	if repo.CooldownDays != nil {
+		days = *repo.CooldownDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// holdForCooldown records a newly detected release as pending and reports
+// whether it is still too young to be announced. A release that supersedes
+// the pending one starts its own timer from its publish date.
+func holdForCooldown(db *gorm.DB, repo *models.Repository, release GitHubRelease, cooldown time.Duration) bool {
+	if release.TagName == repo.LatestRelease || cooldown <= 0 {
+		if repo.PendingRelease != "" && release.TagName == repo.LatestRelease {
+			repo.PendingRelease = ""
+			repo.PendingReleasePublishedAt = nil
+			if err := db.Model(repo).Updates(map[string]interface{}{
+				"pending_release":              "",
+				"pending_release_published_at": nil,
+			}).Error; err != nil {
+				utils.Logger.Errorf("Failed to clear pending release for %s: %v", repo.Name, err)
+			}
+		}
+		return false
+	}
+
+	if repo.PendingRelease != release.TagName || repo.PendingReleasePublishedAt == nil {
+		published, err := time.Parse(time.RFC3339, release.PublishedAt)
+		if err != nil {
+			published = time.Now()
+		}
+		repo.PendingRelease = release.TagName
+		repo.PendingReleasePublishedAt = &published
+		if err := db.Model(repo).Updates(map[string]interface{}{
+			"pending_release":              repo.PendingRelease,
+			"pending_release_published_at": published,
+		}).Error; err != nil {
+			utils.Logger.Errorf("Failed to record pending release for %s: %v", repo.Name, err)
+		}
+	}
+
+	readyAt := repo.PendingReleasePublishedAt.Add(cooldown)
+	if time.Now().Before(readyAt) {
+		utils.Logger.Infof("⏳ %s %s is cooling down until %s", repo.Name, release.TagName, readyAt.Format("Jan 02 2006 3:04 PM"))
+		return true
+	}
+	return false
+}
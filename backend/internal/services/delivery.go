@@ -0,0 +1,232 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+var ErrDeliveryPending = errors.New("the outbox still holds a pending delivery of this release for the channel")
+
+type attemptRecorder interface {
+	setAttemptRecorder(record func(DeliveryAttempt))
+}
+
+type progressTracker interface {
+	setProgress(progress *deliveryProgress)
+}
+
+type deliveryContext struct {
+	scanRunID    uint
+	outboxIDs    []uint
+	notification Notification
+	progress     *deliveryProgress
+}
+
+// deliveryProgress remembers the messages of a notification that already
+// went out, so a retry after a partial failure only sends the rest.
+type deliveryProgress struct {
+	sent []string
+}
+
+func (p *deliveryProgress) add(keys ...string) {
+	for _, key := range keys {
+		if !p.done(key) {
+			p.sent = append(p.sent, key)
+		}
+	}
+}
+
+func (p *deliveryProgress) done(key string) bool {
+	return slices.Contains(p.sent, key)
+}
+
+func messageKey(method, url string, body []byte) string {
+	hash := sha256.Sum256([]byte(method + " " + url + "\n" + string(body)))
+	return hex.EncodeToString(hash[:16])
+}
+
+// recordedNotifier logs a failed delivery that never got as far as a send
+// attempt, such as a template that fails to render.
+type recordedNotifier struct {
+	Notifier
+	record   func(DeliveryAttempt)
+	attempts int
+}
+
+func (n *recordedNotifier) Send(notification Notification) error {
+	err := n.Notifier.Send(notification)
+	if err != nil && n.attempts == 0 {
+		n.record(DeliveryAttempt{Attempt: 1, Err: err})
+	}
+	return err
+}
+
+func newRecordedNotifier(db *gorm.DB, channel models.NotificationChannel, ctx deliveryContext) (Notifier, error) {
+	record := deliveryRecorder(db, channel, ctx)
+	notifier, err := NewNotifier(channel)
+	if err != nil {
+		record(DeliveryAttempt{Attempt: 1, Err: err})
+		return nil, err
+	}
+	recorded := &recordedNotifier{Notifier: notifier, record: record}
+	if recorder, ok := notifier.(attemptRecorder); ok {
+		recorder.setAttemptRecorder(func(attempt DeliveryAttempt) {
+			recorded.attempts++
+			record(attempt)
+		})
+	}
+	if tracker, ok := notifier.(progressTracker); ok && ctx.progress != nil {
+		tracker.setProgress(ctx.progress)
+	}
+	return recorded, nil
+}
+
+func deliveryRecorder(db *gorm.DB, channel models.NotificationChannel, ctx deliveryContext) func(DeliveryAttempt) {
+	notification, err := json.Marshal(ctx.notification)
+	if err != nil {
+		utils.Logger.Warnf("Failed to serialize notification for delivery log: %v", err)
+	}
+	var secrets []string
+	if config, err := DecryptChannelConfig(channel); err == nil {
+		secrets = channelSecrets(config)
+	}
+	return func(attempt DeliveryAttempt) {
+		delivery := models.NotificationDelivery{
+			ChannelID:    channel.ID,
+			ChannelName:  channel.Name,
+			ScanRunID:    ctx.scanRunID,
+			OutboxIDs:    ctx.outboxIDs,
+			Attempt:      attempt.Attempt,
+			StatusCode:   attempt.StatusCode,
+			Success:      attempt.Err == nil,
+			DurationMs:   attempt.Duration.Milliseconds(),
+			Payload:      redactSecrets(attempt.Payload, secrets),
+			Notification: notification,
+		}
+		if attempt.Err != nil {
+			delivery.Error = attempt.Err.Error()
+			utils.Logger.Warnf("Delivery attempt %d to %s failed: %v", attempt.Attempt, channel.Name, attempt.Err)
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			utils.Logger.Errorf("Failed to record delivery attempt for %s: %v", channel.Name, err)
+		}
+	}
+}
+
+// channelSecrets collects the secret values of a decrypted channel config.
+func channelSecrets(config []byte) []string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil
+	}
+	var secrets []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch value := value.(type) {
+		case string:
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+		case map[string]interface{}:
+			for _, nested := range value {
+				collect(nested)
+			}
+		}
+	}
+	for key, value := range fields {
+		if secretConfigKeys[key] {
+			collect(value)
+		}
+	}
+	return secrets
+}
+
+func redactSecrets(payload string, secrets []string) string {
+	for _, secret := range secrets {
+		payload = strings.ReplaceAll(payload, secret, RedactedSecret)
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			payload = strings.ReplaceAll(payload, escaped, url.QueryEscape(RedactedSecret))
+		}
+	}
+	return payload
+}
+
+func ResendDelivery(db *gorm.DB, delivery models.NotificationDelivery) error {
+	if len(delivery.Notification) == 0 {
+		return errors.New("delivery has no stored notification")
+	}
+	var notification Notification
+	if err := json.Unmarshal(delivery.Notification, &notification); err != nil {
+		return errors.New("stored notification is invalid")
+	}
+	var channel models.NotificationChannel
+	if err := db.First(&channel, delivery.ChannelID).Error; err != nil {
+		return errors.New("notification channel no longer exists")
+	}
+
+	dispatchMutex.Lock()
+	defer dispatchMutex.Unlock()
+	if pending, err := pendingRedelivery(db, delivery); err != nil {
+		return err
+	} else if pending {
+		return ErrDeliveryPending
+	}
+
+	notifier, err := newRecordedNotifier(db, channel, deliveryContext{
+		scanRunID:    delivery.ScanRunID,
+		outboxIDs:    delivery.OutboxIDs,
+		notification: notification,
+	})
+	if err != nil {
+		return err
+	}
+	if err := notifier.Send(notification); err != nil {
+		return err
+	}
+
+	if len(delivery.OutboxIDs) > 0 {
+		var entries []models.NotificationOutbox
+		if err := db.Where("id IN ? AND status IN ?", delivery.OutboxIDs, []string{OutboxStatusPending, OutboxStatusFailed}).
+			Find(&entries).Error; err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return acknowledgeOutboxEntries(db, entries)
+		}
+	}
+	return nil
+}
+
+// pendingRedelivery reports whether another outbox entry will still deliver
+// one of the releases of delivery to its channel.
+func pendingRedelivery(db *gorm.DB, delivery models.NotificationDelivery) (bool, error) {
+	if len(delivery.OutboxIDs) == 0 {
+		return false, nil
+	}
+	var entries []models.NotificationOutbox
+	if err := db.Where("id IN ?", delivery.OutboxIDs).Find(&entries).Error; err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		var count int64
+		if err := db.Model(&models.NotificationOutbox{}).
+			Where("channel_id = ? AND repository_id = ? AND version = ? AND status = ?", delivery.ChannelID, entry.RepositoryID, entry.Version, OutboxStatusPending).
+			Where("id NOT IN ?", delivery.OutboxIDs).
+			Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	DeliveryModeImmediate = "immediate"
+	DeliveryModeDigest    = "digest"
+
+	ungroupedLabel = "Ungrouped"
+)
+
+var digestSchedules = struct {
+	sync.Mutex
+	scheduler *cron.Cron
+	entries   map[uint]cron.EntryID
+}{entries: map[uint]cron.EntryID{}}
+
+type UpdateGroup struct {
+	Name     string
+	Severity string
+	Updates  []ReleaseUpdate
+}
+
+func ValidateDeliveryMode(mode, schedule string) error {
+	switch mode {
+	case DeliveryModeImmediate:
+		return nil
+	case DeliveryModeDigest:
+		if schedule == "" {
+			return errors.New("Digest channels need a digest schedule")
+		}
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return fmt.Errorf("Invalid digest schedule: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("Unknown delivery mode %q", mode)
+}
+
+func StartDigestSchedules(db *gorm.DB, scheduler *cron.Cron) {
+	digestSchedules.Lock()
+	digestSchedules.scheduler = scheduler
+	digestSchedules.Unlock()
+
+	var channels []models.NotificationChannel
+	if err := db.Where("delivery_mode = ?", DeliveryModeDigest).Find(&channels).Error; err != nil {
+		utils.Logger.Errorf("Failed to load digest channels: %v", err)
+		return
+	}
+	for _, channel := range channels {
+		if err := ScheduleDigest(db, channel); err != nil {
+			utils.Logger.Errorf("Failed to schedule digest for %s: %v", channel.Name, err)
+		}
+	}
+}
+
+func ScheduleDigest(db *gorm.DB, channel models.NotificationChannel) error {
+	digestSchedules.Lock()
+	defer digestSchedules.Unlock()
+
+	if entryID, ok := digestSchedules.entries[channel.ID]; ok {
+		digestSchedules.scheduler.Remove(entryID)
+		delete(digestSchedules.entries, channel.ID)
+	}
+	if digestSchedules.scheduler == nil || channel.DeliveryMode != DeliveryModeDigest || !channel.Enabled {
+		return nil
+	}
+
+	channelID := channel.ID
+	entryID, err := digestSchedules.scheduler.AddFunc(channel.DigestSchedule, func() {
+		if IsSchedulerPaused(db) {
+			return
+		}
+		SendDigest(db, channelID)
+	})
+	if err != nil {
+		return err
+	}
+	digestSchedules.entries[channel.ID] = entryID
+	utils.Logger.Infof("🗞️ Digest for %s scheduled: %s", channel.Name, channel.DigestSchedule)
+	return nil
+}
+
+func UnscheduleDigest(channelID uint) {
+	digestSchedules.Lock()
+	defer digestSchedules.Unlock()
+	if entryID, ok := digestSchedules.entries[channelID]; ok {
+		digestSchedules.scheduler.Remove(entryID)
+		delete(digestSchedules.entries, channelID)
+	}
+}
+
+func SendDigest(db *gorm.DB, channelID uint) int {
+	dispatchMutex.Lock()
+	defer dispatchMutex.Unlock()
+
+	var entries []models.NotificationOutbox
+	if err := db.Where("channel_id = ? AND status = ?", channelID, OutboxStatusPending).
+		Order("id").Find(&entries).Error; err != nil {
+		utils.Logger.Errorf("Failed to load digest entries: %v", err)
+		return 0
+	}
+	if err := db.Model(&models.NotificationChannel{}).Where("id = ?", channelID).
+		Update("last_digest_at", time.Now()).Error; err != nil {
+		utils.Logger.Warnf("Failed to record digest time: %v", err)
+	}
+	if len(entries) == 0 {
+		return 0
+	}
+	dispatchChannel(db, channelID, entries)
+	return len(entries)
+}
+
+func groupUpdates(updates []ReleaseUpdate) []UpdateGroup {
+	var groups []UpdateGroup
+	for _, update := range updates {
+		name := update.Group
+		if name == "" {
+			name = ungroupedLabel
+		}
+		severity := update.Severity
+		if severity == "" {
+			severity = SeverityUnknown
+		}
+		index := slices.IndexFunc(groups, func(group UpdateGroup) bool {
+			return group.Name == name && group.Severity == severity
+		})
+		if index == -1 {
+			groups = append(groups, UpdateGroup{Name: name, Severity: severity})
+			index = len(groups) - 1
+		}
+		groups[index].Updates = append(groups[index].Updates, update)
+	}
+	slices.SortStableFunc(groups, func(a, b UpdateGroup) int {
+		if a.Name != b.Name {
+			switch {
+			case a.Name == ungroupedLabel:
+				return 1
+			case b.Name == ungroupedLabel:
+				return -1
+			}
+			return strings.Compare(a.Name, b.Name)
+		}
+		return severityRank(b.Severity) - severityRank(a.Severity)
+	})
+	return groups
+}
@@ -1,222 +1,193 @@
 package services
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"surveillance/internal/models"
-	"surveillance/internal/utils"
-	"time"
-
-	"gorm.io/gorm"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
 )
 
-func SendDiscordNotification(db *gorm.DB, updateDetails string, scanType string) error {
-	var settings models.NotificationSettings
-	if err := db.First(&settings).Error; err != nil {
-		utils.Logger.Errorf("Failed to retrieve notification settings: %v", err)
-		return err
-	}
-	if settings.WebhookURL == "" {
-		utils.Logger.Info("No Discord webhook URL is set; skipping notification.")
-		return nil
-	}
+const (
+	discordMaxTitleLength       = 256
+	discordMaxDescriptionLength = 4096
+	discordMaxFooterLength      = 2048
+	discordMaxEmbedsPerMessage  = 10
+	discordMaxMessageLength     = 6000
+)
 
-	type EmbedAuthor struct {
-		Name    string `json:"name"`
-		IconURL string `json:"icon_url,omitempty"`
-	}
+var discordMentionPattern = regexp.MustCompile(`^(?:@everyone|@here|<@&(\d+)>|<@!?(\d+)>)$`)
 
-	type EmbedFooter struct {
-		Text string `json:"text"`
-	}
+type DiscordConfig struct {
+	WebhookURL    string `json:"webhookUrl"`
+	DiscordName   string `json:"discordName"`
+	DiscordAvatar string `json:"discordAvatar"`
+	PingType      string `json:"pingType"`
+}
 
-	type Embed struct {
-		Title       string      `json:"title"`
-		Color       int         `json:"color"`
-		Description string      `json:"description"`
-		Footer      EmbedFooter `json:"footer"`
-		Author      EmbedAuthor `json:"author"`
-	}
+type DiscordNotifier struct {
+	httpSender
+	config    DiscordConfig
+	templates messageTemplates
+}
 
-	type Payload struct {
-		Username  string  `json:"username"`
-		AvatarURL string  `json:"avatar_url,omitempty"`
-		Content   string  `json:"content"`
-		Embeds    []Embed `json:"embeds"`
-	}
+type discordEmbedAuthor struct {
+	Name    string `json:"name"`
+	IconURL string `json:"icon_url,omitempty"`
+}
 
-	ping := ""
-	switch settings.PingType {
-	case "@everyone":
-		ping = "@everyone "
-	case "@here":
-		ping = "@here "
-	}
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
 
-	scanTypeString := "Scheduled Scan"
-	if scanType == "Manual" {
-		scanTypeString = "Manual Scan"
-	} else if scanType == "Test" {
-		scanTypeString = "Test Scan"
-	}
+type discordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Color       int                 `json:"color"`
+	Description string              `json:"description"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+	Author      discordEmbedAuthor  `json:"author"`
+}
 
-	currentTime := time.Now().Format("Today at 3:04 PM")
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+	Roles []string `json:"roles,omitempty"`
+	Users []string `json:"users,omitempty"`
+}
 
-	discordName := settings.DiscordName
-	if discordName == "" {
-		discordName = "Surveillance Bot"
-	}
+type discordPayload struct {
+	Username        string                 `json:"username"`
+	AvatarURL       string                 `json:"avatar_url,omitempty"`
+	Content         string                 `json:"content"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
 
-	embed := Embed{
-		Title:       "Repository Updates Available",
-		Color:       3447003,
-		Description: updateDetails,
-		Footer: EmbedFooter{
-			Text: fmt.Sprintf("%s • %s", scanTypeString, currentTime),
-		},
-		Author: EmbedAuthor{
-			Name:    "Surveillance",
-			IconURL: settings.DiscordAvatar,
-		},
+func newDiscordNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config DiscordConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Discord config: %w", err)
 	}
-
-	payload := Payload{
-		Username:  discordName,
-		AvatarURL: settings.DiscordAvatar,
-		Content:   ping,
-		Embeds:    []Embed{embed},
+	if config.WebhookURL == "" {
+		return nil, errors.New("Discord webhook URL is not set")
 	}
-
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		utils.Logger.Errorf("Failed to marshal Discord payload: %v", err)
-		return err
+	if config.DiscordName == "" {
+		config.DiscordName = "Surveillance Bot"
 	}
+	return &DiscordNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
 
-	req, err := http.NewRequest("POST", settings.WebhookURL, bytes.NewBuffer(jsonPayload))
+func (d *DiscordNotifier) Send(notification Notification) error {
+	message, err := d.templates.render(notification)
 	if err != nil {
-		utils.Logger.Errorf("Failed to create Discord notification request: %v", err)
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		utils.Logger.Errorf("Failed to send Discord notification: %v", err)
-		return err
+	mention := notification.Mention
+	if mention == "" && !notification.Routed {
+		mention = d.config.PingType
 	}
-	defer resp.Body.Close()
+	ping, allowedMentions := discordMentions(mention)
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		utils.Logger.Errorf("Discord notification failed with status: %d", resp.StatusCode)
-		return err
+	payloads := d.buildPayloads(message, ping, allowedMentions)
+	for i, payload := range payloads {
+		if err := d.postJSON(d.config.WebhookURL, payload); err != nil {
+			return fmt.Errorf("message %d of %d: %w", i+1, len(payloads), err)
+		}
 	}
-
-	utils.Logger.Info("Discord notification sent successfully.")
 	return nil
 }
 
-func SendTestDiscordNotification(db *gorm.DB) error {
-	var settings models.NotificationSettings
-	if err := db.First(&settings).Error; err != nil {
-		utils.Logger.Errorf("Failed to retrieve notification settings: %v", err)
-		return err
-	}
-	if settings.WebhookURL == "" {
-		utils.Logger.Info("No Discord webhook URL is set; skipping notification.")
-		return nil
-	}
-
-	type EmbedAuthor struct {
-		Name    string `json:"name"`
-		IconURL string `json:"icon_url,omitempty"`
-	}
-
-	type EmbedFooter struct {
-		Text string `json:"text"`
-	}
-
-	type Embed struct {
-		Title       string      `json:"title"`
-		Color       int         `json:"color"`
-		Description string      `json:"description"`
-		Footer      EmbedFooter `json:"footer"`
-		Author      EmbedAuthor `json:"author"`
-	}
-
-	type Payload struct {
-		Username  string  `json:"username"`
-		AvatarURL string  `json:"avatar_url,omitempty"`
-		Content   string  `json:"content"`
-		Embeds    []Embed `json:"embeds"`
-	}
-
-	exampleUpdates := "- [facebook/react](https://github.com/facebook/react): 2.5.1 → v19.0.0\n"
-	scanTypeString := "Test Scan"
-
-	currentTime := time.Now().Format("Today at 3:04 PM")
-
-	discordName := settings.DiscordName
-	if discordName == "" {
-		discordName = "Surveillance Bot"
-	}
-
-	ping := ""
-	switch settings.PingType {
-	case "@everyone":
-		ping = "@everyone "
-	case "@here":
-		ping = "@here "
-	}
-
-	embed := Embed{
-		Title:       "Repository Updates Available",
-		Color:       3447003,
-		Description: exampleUpdates,
-		Footer: EmbedFooter{
-			Text: fmt.Sprintf("%s • %s", scanTypeString, currentTime),
-		},
-		Author: EmbedAuthor{
-			Name:    "Surveillance",
-			IconURL: settings.DiscordAvatar,
-		},
-	}
-
-	payload := Payload{
-		Username:  discordName,
-		AvatarURL: settings.DiscordAvatar,
-		Content:   ping,
-		Embeds:    []Embed{embed},
-	}
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		utils.Logger.Errorf("Failed to marshal Discord payload: %v", err)
-		return err
-	}
-	req, err := http.NewRequest("POST", settings.WebhookURL, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		utils.Logger.Errorf("Failed to create Discord notification request: %v", err)
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
+func (d *DiscordNotifier) buildPayloads(message Message, ping string, allowedMentions discordAllowedMentions) []discordPayload {
+	title := truncateRunes(message.Title, discordMaxTitleLength)
+	footer := truncateRunes(message.Footer, discordMaxFooterLength)
+	author := discordEmbedAuthor{Name: "Surveillance", IconURL: d.config.DiscordAvatar}
+	overhead := utf8.RuneCountInString(title) + utf8.RuneCountInString(footer) + utf8.RuneCountInString(author.Name)
+	descriptionLimit := min(discordMaxDescriptionLength, discordMaxMessageLength-overhead)
+
+	lines := strings.Split(message.Body, "\n")
+	for i := range lines {
+		lines[i] = truncateRunes(lines[i], descriptionLimit)
+	}
+	descriptions := chunkLines(lines, "\n", descriptionLimit)
+	if len(descriptions) == 0 {
+		descriptions = []string{""}
+	}
+
+	var payloads []discordPayload
+	var current []discordEmbed
+	size := 0
+	flush := func() {
+		current[len(current)-1].Footer = &discordEmbedFooter{Text: footer}
+		content := ""
+		if len(payloads) == 0 {
+			content = ping
+		}
+		payloads = append(payloads, discordPayload{
+			Username:        d.config.DiscordName,
+			AvatarURL:       d.config.DiscordAvatar,
+			Content:         content,
+			Embeds:          current,
+			AllowedMentions: allowedMentions,
+		})
+		current = nil
+		size = 0
+	}
+	for _, description := range descriptions {
+		embedSize := utf8.RuneCountInString(description) + utf8.RuneCountInString(author.Name)
+		if len(current) > 0 && (len(current) == discordMaxEmbedsPerMessage || size+embedSize > discordMaxMessageLength-utf8.RuneCountInString(footer)) {
+			flush()
+		}
+		embed := discordEmbed{Color: 3447003, Description: description, Author: author}
+		if len(current) == 0 {
+			embed.Title = title
+			embedSize += utf8.RuneCountInString(title)
+		}
+		current = append(current, embed)
+		size += embedSize
+	}
+	flush()
+	return payloads
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		utils.Logger.Errorf("Failed to send Discord notification: %v", err)
-		return err
-	}
-	defer resp.Body.Close()
+// discordMentions keeps only well-formed mentions from text and builds the
+// allowed_mentions that lets exactly those ping. Everything else in the
+// message, changelogs included, is rendered inert.
+func discordMentions(text string) (string, discordAllowedMentions) {
+	allowed := discordAllowedMentions{Parse: []string{}}
+	var mentions []string
+	for _, field := range strings.Fields(text) {
+		match := discordMentionPattern.FindStringSubmatch(field)
+		if match == nil || slices.Contains(mentions, field) {
+			continue
+		}
+		mentions = append(mentions, field)
+		switch {
+		case match[1] != "":
+			if !slices.Contains(allowed.Roles, match[1]) {
+				allowed.Roles = append(allowed.Roles, match[1])
+			}
+		case match[2] != "":
+			if !slices.Contains(allowed.Users, match[2]) {
+				allowed.Users = append(allowed.Users, match[2])
+			}
+		default:
+			if !slices.Contains(allowed.Parse, "everyone") {
+				allowed.Parse = append(allowed.Parse, "everyone")
+			}
+		}
+	}
+	if len(mentions) == 0 {
+		return "", allowed
+	}
+	return strings.Join(mentions, " ") + " ", allowed
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		utils.Logger.Errorf("Discord notification failed with status: %d", resp.StatusCode)
-		return err
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
 	}
-
-	utils.Logger.Info("Test Discord notification sent successfully.")
-	return nil
+	return string(runes[:limit-1]) + "…"
 }
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDiscordMentions(t *testing.T) {
+	tests := []struct {
+		text, ping   string
+		parse        []string
+		roles, users []string
+	}{
+		{"", "", []string{}, nil, nil},
+		{"@here", "@here ", []string{"everyone"}, nil, nil},
+		{"@everyone @here", "@everyone @here ", []string{"everyone"}, nil, nil},
+		{"<@&123>", "<@&123> ", []string{}, []string{"123"}, nil},
+		{"<@456> <@!456>", "<@456> <@!456> ", []string{}, nil, []string{"456"}},
+		{"@here @here <@&1> <@&1>", "@here <@&1> ", []string{"everyone"}, []string{"1"}, nil},
+		{"@ops please look", "", []string{}, nil, nil},
+		{"<@abc> <#123> @HERE", "", []string{}, nil, nil},
+		{"hi <@&7> there <@8>", "<@&7> <@8> ", []string{}, []string{"7"}, []string{"8"}},
+	}
+	for _, tt := range tests {
+		ping, allowed := discordMentions(tt.text)
+		if ping != tt.ping || !slices.Equal(allowed.Parse, tt.parse) || !slices.Equal(allowed.Roles, tt.roles) || !slices.Equal(allowed.Users, tt.users) {
+			t.Errorf("discordMentions(%q) = (%q, %+v), want (%q, parse %v roles %v users %v)", tt.text, ping, allowed, tt.ping, tt.parse, tt.roles, tt.users)
+		}
+	}
+}
+
+func TestDiscordBuildPayloads(t *testing.T) {
+	longLines := make([]string, 100)
+	for i := range longLines {
+		longLines[i] = strings.Repeat("x", 99)
+	}
+	tests := []struct {
+		name      string
+		title     string
+		body      string
+		payloads  int
+		embeds    int
+		truncated bool
+	}{
+		{"empty body", "T", "", 1, 1, false},
+		{"short body", "T", "line one\nline two", 1, 1, false},
+		{"one chunk per payload", "T", strings.Join(longLines, "\n"), 3, 3, false},
+		{"two chunks fit one payload", "T", strings.Join(longLines[:45], "\n"), 1, 2, false},
+		{"oversized line", "T", strings.Repeat("y", 5000), 1, 1, true},
+		{"oversized title", strings.Repeat("t", 300), "body", 1, 1, false},
+	}
+	d := &DiscordNotifier{config: DiscordConfig{DiscordName: "Bot"}}
+	for _, tt := range tests {
+		allowed := discordAllowedMentions{Parse: []string{"everyone"}}
+		payloads := d.buildPayloads(Message{Title: tt.title, Body: tt.body, Footer: "F"}, "@here ", allowed)
+		embeds := 0
+		var descriptions []string
+		for i, payload := range payloads {
+			if (i == 0) != (payload.Content == "@here ") {
+				t.Errorf("%s: payload %d content %q, want the ping only on the first", tt.name, i, payload.Content)
+			}
+			if !slices.Equal(payload.AllowedMentions.Parse, allowed.Parse) {
+				t.Errorf("%s: payload %d lost its allowed mentions", tt.name, i)
+			}
+			if len(payload.Embeds) > discordMaxEmbedsPerMessage {
+				t.Errorf("%s: payload %d has %d embeds", tt.name, i, len(payload.Embeds))
+			}
+			size := 0
+			for j, embed := range payload.Embeds {
+				size += utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description) + utf8.RuneCountInString(embed.Author.Name)
+				if embed.Footer != nil {
+					size += utf8.RuneCountInString(embed.Footer.Text)
+				}
+				if utf8.RuneCountInString(embed.Title) > discordMaxTitleLength || utf8.RuneCountInString(embed.Description) > discordMaxDescriptionLength {
+					t.Errorf("%s: payload %d embed %d exceeds the title or description limit", tt.name, i, j)
+				}
+				if (j == 0) != (embed.Title != "") {
+					t.Errorf("%s: payload %d embed %d title %q, want it only on the first embed", tt.name, i, j, embed.Title)
+				}
+				if (j == len(payload.Embeds)-1) != (embed.Footer != nil) {
+					t.Errorf("%s: payload %d embed %d footer %v, want it only on the last embed", tt.name, i, j, embed.Footer)
+				}
+				descriptions = append(descriptions, embed.Description)
+			}
+			if size > discordMaxMessageLength {
+				t.Errorf("%s: payload %d has %d characters", tt.name, i, size)
+			}
+			embeds += len(payload.Embeds)
+		}
+		if len(payloads) != tt.payloads || embeds != tt.embeds {
+			t.Errorf("%s: %d payloads with %d embeds, want %d with %d", tt.name, len(payloads), embeds, tt.payloads, tt.embeds)
+		}
+		joined := strings.Join(descriptions, "\n")
+		if tt.truncated && !strings.HasSuffix(joined, "…") {
+			t.Errorf("%s: oversized line was not truncated", tt.name)
+		}
+		if !tt.truncated && joined != tt.body {
+			t.Errorf("%s: descriptions do not reproduce the body", tt.name)
+		}
+	}
+}
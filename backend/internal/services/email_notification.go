@@ -0,0 +1,294 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EmailSecurityNone     = "none"
+	EmailSecuritySTARTTLS = "starttls"
+	EmailSecurityTLS      = "tls"
+)
+
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>{{.Title}}</h2>
+{{- if .Mention}}
+<p>{{.Mention}}</p>
+{{- end}}
+{{- if .Body}}
+<div style="white-space: pre-wrap;">{{.Body}}</div>
+{{- else}}
+<table cellpadding="6" cellspacing="0" border="1" style="border-collapse: collapse;">
+<thead>
+<tr><th align="left">Repository</th><th align="left">Old version</th><th align="left">New version</th><th align="left">Release</th></tr>
+</thead>
+<tbody>
+{{- range .Updates}}
+<tr><td><a href="{{.URL}}">{{.Repository}}</a></td><td>{{.PreviousVersion}}</td><td>{{.NewVersion}}</td><td><a href="{{.ReleaseURL}}">View release</a></td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+<p style="color: #777777; font-size: small;">{{.Footer}}</p>
+</body>
+</html>
+`))
+
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Security string   `json:"security"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+type EmailNotifier struct {
+	config    EmailConfig
+	from      *mail.Address
+	to        []*mail.Address
+	templates messageTemplates
+	onAttempt func(DeliveryAttempt)
+	capture   func(PayloadPreview)
+}
+
+func newEmailNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config EmailConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid email config: %w", err)
+	}
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("SMTP host and from address are required")
+	}
+	if len(config.To) == 0 {
+		return nil, errors.New("at least one email recipient is required")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", config.From, err)
+	}
+	to := make([]*mail.Address, 0, len(config.To))
+	for _, recipient := range config.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to = append(to, address)
+	}
+	if config.Security == "" {
+		config.Security = EmailSecuritySTARTTLS
+	}
+	switch config.Security {
+	case EmailSecurityNone, EmailSecuritySTARTTLS:
+		if config.Port == 0 {
+			config.Port = 587
+		}
+	case EmailSecurityTLS:
+		if config.Port == 0 {
+			config.Port = 465
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SMTP security mode: %s", config.Security)
+	}
+	return &EmailNotifier{config: config, from: from, to: to, templates: templates}, nil
+}
+
+func (e *EmailNotifier) Send(notification Notification) error {
+	message, err := e.buildMessage(notification)
+	if err != nil {
+		return err
+	}
+	if e.capture != nil {
+		e.capture(PayloadPreview{Method: "SMTP", ContentType: "message/rfc822", Body: string(message)})
+		return nil
+	}
+	start := time.Now()
+	err = e.deliver(message)
+	if e.onAttempt != nil {
+		statusCode := 250
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) {
+			statusCode = smtpErr.Code
+		} else if err != nil {
+			statusCode = 0
+		}
+		e.onAttempt(DeliveryAttempt{Attempt: 1, StatusCode: statusCode, Err: err, Duration: time.Since(start), Payload: string(message)})
+	}
+	return err
+}
+
+func (e *EmailNotifier) setAttemptRecorder(record func(DeliveryAttempt)) {
+	e.onAttempt = record
+}
+
+func (e *EmailNotifier) capturePayloads(capture func(PayloadPreview)) {
+	e.capture = capture
+}
+
+func (e *EmailNotifier) buildMessage(notification Notification) ([]byte, error) {
+	rendered, err := e.templates.render(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain strings.Builder
+	plain.WriteString(rendered.Title + "\r\n\r\n")
+	if notification.Mention != "" {
+		plain.WriteString(notification.Mention + "\r\n\r\n")
+	}
+	if rendered.CustomBody {
+		plain.WriteString(strings.ReplaceAll(rendered.Body, "\n", "\r\n") + "\r\n")
+	}
+	for _, update := range notification.Updates {
+		if rendered.CustomBody {
+			break
+		}
+		plain.WriteString(fmt.Sprintf("- %s: %s -> %s\r\n  %s\r\n", update.Repository, update.PreviousVersion, update.NewVersion, update.ReleaseURL()))
+	}
+	plain.WriteString("\r\n" + rendered.Footer + "\r\n")
+
+	htmlData := map[string]interface{}{
+		"Title":   rendered.Title,
+		"Updates": notification.Updates,
+		"Footer":  rendered.Footer,
+		"Mention": notification.Mention,
+	}
+	if rendered.CustomBody {
+		htmlData["Body"] = rendered.Body
+	}
+	var htmlBody bytes.Buffer
+	if err := emailHTMLTemplate.Execute(&htmlBody, htmlData); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", plain.String()},
+		{"text/html; charset=UTF-8", htmlBody.String()},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	recipients := make([]string, len(e.to))
+	for i, address := range e.to {
+		recipients[i] = address.String()
+	}
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", e.from.String()},
+		{"To", strings.Join(recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", emailSubject(rendered.Title, len(notification.Updates)))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", writer.Boundary())},
+	}
+	for _, header := range headers {
+		message.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+func emailSubject(title string, updates int) string {
+	switch updates {
+	case 0:
+		return title
+	case 1:
+		return title + " (1 update)"
+	}
+	return fmt.Sprintf("%s (%d updates)", title, updates)
+}
+
+func (e *EmailNotifier) deliver(message []byte) error {
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	tlsConfig := &tls.Config{ServerName: e.config.Host}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if e.config.Security == EmailSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if e.config.Security == EmailSecuritySTARTTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if e.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(e.from.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, recipient := range e.to {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", recipient.Address, err)
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := data.Write(message); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := data.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	GitHubSourceOrg     = "org"
+	GitHubSourceUser    = "user"
+	GitHubSourceStarred = "starred"
+
+	maxRepositoryPages = 50
+)
+
+var (
+	githubOwnerPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	nextLinkPattern    = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+type GitHubRepository struct {
+	FullName    string   `json:"full_name"`
+	HTMLURL     string   `json:"html_url"`
+	Description string   `json:"description"`
+	Archived    bool     `json:"archived"`
+	Fork        bool     `json:"fork"`
+	Topics      []string `json:"topics"`
+}
+
+// GitHubRepositoryFilter narrows a listing. Archived repositories and forks
+// are left out unless included; with Topics set, a repository needs at least
+// one of them.
+type GitHubRepositoryFilter struct {
+	IncludeArchived bool
+	IncludeForks    bool
+	Topics          []string
+}
+
+func (f GitHubRepositoryFilter) Matches(repo GitHubRepository) bool {
+	if repo.Archived && !f.IncludeArchived || repo.Fork && !f.IncludeForks {
+		return false
+	}
+	if len(f.Topics) == 0 {
+		return true
+	}
+	for _, topic := range repo.Topics {
+		if slices.Contains(f.Topics, strings.ToLower(topic)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListGitHubRepositories pages through the repositories of an organization,
+// the repositories a user owns or the ones they starred, following the Link
+// header.
+func ListGitHubRepositories(source, owner, githubToken string) ([]GitHubRepository, error) {
+	if !githubOwnerPattern.MatchString(owner) {
+		return nil, fmt.Errorf("Invalid GitHub account %q", owner)
+	}
+	var url string
+	switch source {
+	case GitHubSourceOrg:
+		url = "https://api.github.com/orgs/" + owner + "/repos?type=all&per_page=100"
+	case GitHubSourceUser:
+		url = "https://api.github.com/users/" + owner + "/repos?type=owner&per_page=100"
+	case GitHubSourceStarred:
+		url = "https://api.github.com/users/" + owner + "/starred?per_page=100"
+	default:
+		return nil, fmt.Errorf("Unknown source %q; use org, user or starred", source)
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	var repos []GitHubRepository
+	for page := 0; url != "" && page < maxRepositoryPages; page++ {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if githubToken != "" {
+			req.Header.Set("Authorization", "Bearer "+githubToken)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, newGitHubError(resp)
+		}
+		var pageRepos []GitHubRepository
+		err = json.NewDecoder(resp.Body).Decode(&pageRepos)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		repos = append(repos, pageRepos...)
+		url = nextPageURL(resp.Header.Get("Link"))
+	}
+	return repos, nil
+}
+
+func nextPageURL(link string) string {
+	match := nextLinkPattern.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
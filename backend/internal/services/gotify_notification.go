@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type GotifyConfig struct {
+	ServerURL string `json:"serverUrl"`
+	AppToken  string `json:"appToken"`
+	// Priority 0-10 overrides the priority derived from the update severity,
+	// which leaving it unset keeps. 0 delivers silently.
+	Priority *int `json:"priority"`
+}
+
+type GotifyNotifier struct {
+	httpSender
+	config    GotifyConfig
+	templates messageTemplates
+}
+
+type gotifyPayload struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+func newGotifyNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config GotifyConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Gotify config: %w", err)
+	}
+	if config.ServerURL == "" || config.AppToken == "" {
+		return nil, errors.New("Gotify server URL and app token are required")
+	}
+	if config.Priority != nil && (*config.Priority < 0 || *config.Priority > 10) {
+		return nil, errors.New("Gotify priority must be between 0 and 10, or unset to follow the update severity")
+	}
+	config.ServerURL = strings.TrimSuffix(config.ServerURL, "/")
+	return &GotifyNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (g *GotifyNotifier) Send(notification Notification) error {
+	message, err := g.templates.render(notification)
+	if err != nil {
+		return err
+	}
+	priority := gotifyPriority(notification.HighestSeverity())
+	if g.config.Priority != nil {
+		priority = *g.config.Priority
+	}
+	extras := map[string]interface{}{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	if click := clickURL(notification); click != "" {
+		extras["client::notification"] = map[string]interface{}{"click": map[string]string{"url": click}}
+	}
+	payload := gotifyPayload{
+		Title:    message.Title,
+		Message:  withMention(notification.Mention, message.Body) + "\n\n_" + message.Footer + "_",
+		Priority: priority,
+		Extras:   extras,
+	}
+	headers := map[string]string{"X-Gotify-Key": g.config.AppToken}
+	return g.sendJSON("POST", g.config.ServerURL+"/message", headers, payload)
+}
+
+func gotifyPriority(severity string) int {
+	switch severity {
+	case SeverityPatch:
+		return 2
+	case SeverityMajor:
+		return 7
+	case SeverityCritical:
+		return 9
+	}
+	return 5
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"surveillance/internal/utils"
+	"time"
+)
+
+const (
+	deliveryMaxAttempts = 3
+	deliveryMaxWait     = 30 * time.Second
+)
+
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+type DeliveryAttempt struct {
+	Attempt    int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+	Payload    string
+}
+
+type httpSender struct {
+	client    *http.Client
+	onAttempt func(DeliveryAttempt)
+	capture   func(PayloadPreview)
+	progress  *deliveryProgress
+}
+
+func newHTTPSender() httpSender {
+	return httpSender{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *httpSender) setAttemptRecorder(record func(DeliveryAttempt)) {
+	s.onAttempt = record
+}
+
+func (s *httpSender) setProgress(progress *deliveryProgress) {
+	s.progress = progress
+}
+
+func (s *httpSender) capturePayloads(capture func(PayloadPreview)) {
+	s.capture = capture
+}
+
+func (s httpSender) postJSON(url string, payload interface{}) error {
+	return s.sendJSON("POST", url, nil, payload)
+}
+
+func (s httpSender) sendJSON(method, url string, headers map[string]string, payload interface{}) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return s.send(method, url, "application/json", jsonPayload, headers)
+}
+
+func (s httpSender) send(method, url, contentType string, body []byte, headers map[string]string) error {
+	if s.capture != nil {
+		s.capture(PayloadPreview{Method: method, ContentType: contentType, Body: previewBody(contentType, body)})
+		return nil
+	}
+	key := messageKey(method, url, body)
+	if s.progress != nil && s.progress.done(key) {
+		return nil
+	}
+	var err error
+	for attempt := 1; attempt <= deliveryMaxAttempts; attempt++ {
+		req, reqErr := http.NewRequest(method, url, bytes.NewReader(body))
+		if reqErr != nil {
+			return fmt.Errorf("failed to create request: %w", reqErr)
+		}
+		req.Header.Set("Content-Type", contentType)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		start := time.Now()
+		var statusCode int
+		statusCode, err = s.do(req)
+		if s.onAttempt != nil {
+			s.onAttempt(DeliveryAttempt{Attempt: attempt, StatusCode: statusCode, Err: err, Duration: time.Since(start), Payload: string(body)})
+		}
+		if err == nil || !isRetryable(err) || attempt == deliveryMaxAttempts {
+			break
+		}
+
+		wait := time.Duration(attempt) * time.Second
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+		}
+		if wait > deliveryMaxWait {
+			utils.Logger.Warnf("Delivery to %s asked to wait %s, giving up", req.URL.Host, wait)
+			break
+		}
+		utils.Logger.Warnf("Delivery attempt %d to %s failed (%v), retrying in %s", attempt, req.URL.Host, err, wait)
+		time.Sleep(wait)
+	}
+	if err == nil && s.progress != nil {
+		s.progress.add(key)
+	}
+	return err
+}
+
+func (s httpSender) do(req *http.Request) (int, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, &HTTPStatusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(bytes.TrimSpace(body)),
+			RetryAfter: retryAfter(resp, body),
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if wait := rateLimitResetAfter(resp.Header); wait > 0 && wait <= deliveryMaxWait {
+			utils.Logger.Infof("Rate limit bucket for %s exhausted, waiting %s", req.URL.Host, wait)
+			time.Sleep(wait)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func retryAfter(resp *http.Response, body []byte) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	var rateLimit struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if json.Unmarshal(body, &rateLimit) == nil && rateLimit.RetryAfter > 0 {
+		return time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	}
+	if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Retry-After")); err == nil {
+		return time.Until(date)
+	}
+	return rateLimitResetAfter(resp.Header)
+}
+
+func rateLimitResetAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if reset, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset"), 64); err == nil && reset > 0 {
+		return time.Until(time.Unix(0, int64(reset*float64(time.Second))))
+	}
+	return 0
+}
+
+// isRetryable retries rate limits, server errors and connections that failed
+// before the request was written. A timeout or reset after that may mean the
+// webhook already posted the message, so it is not retried.
+func isRetryable(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
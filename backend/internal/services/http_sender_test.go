@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &HTTPStatusError{StatusCode: http.StatusBadGateway}, true},
+		{"client error", &HTTPStatusError{StatusCode: http.StatusBadRequest}, false},
+		{"dial error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"read error", &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, false},
+		{"other error", errors.New("context deadline exceeded"), false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: isRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSendDoesNotRetryTimeouts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	sender := httpSender{client: &http.Client{Timeout: 20 * time.Millisecond}}
+	if err := sender.postJSON(server.URL, map[string]string{"content": "hi"}); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("sent %d requests after a timeout, want 1", got)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"surveillance/internal/models"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	IgnoreTypeTag    = "tag"
+	IgnoreTypeRange  = "range"
+	IgnoreTypeSnooze = "snooze"
+)
+
+func ValidateIgnore(ignore models.RepositoryIgnore) error {
+	switch ignore.Type {
+	case IgnoreTypeTag:
+		if ignore.Value == "" {
+			return errors.New("A release tag is required")
+		}
+	case IgnoreTypeRange:
+		if _, err := semver.NewConstraint(ignore.Value); err != nil {
+			return fmt.Errorf("Invalid version range %q: %v", ignore.Value, err)
+		}
+	case IgnoreTypeSnooze:
+		if ignore.Until == nil || !ignore.Until.After(time.Now()) {
+			return errors.New("Snooze needs a date in the future")
+		}
+	default:
+		return fmt.Errorf("Unknown ignore type %q", ignore.Type)
+	}
+	return nil
+}
+
+// MatchIgnore returns the first ignore that silences version, if any. An
+// active snooze silences every version.
+func MatchIgnore(ignores []models.RepositoryIgnore, version string, now time.Time) *models.RepositoryIgnore {
+	for i, ignore := range ignores {
+		switch ignore.Type {
+		case IgnoreTypeTag:
+			if version == ignore.Value || strings.TrimPrefix(version, "v") == strings.TrimPrefix(ignore.Value, "v") {
+				return &ignores[i]
+			}
+		case IgnoreTypeRange:
+			constraint, err := semver.NewConstraint(ignore.Value)
+			if err != nil {
+				continue
+			}
+			if parsed, err := semver.NewVersion(version); err == nil && constraint.Check(parsed) {
+				return &ignores[i]
+			}
+		case IgnoreTypeSnooze:
+			if ignore.Until != nil && now.Before(*ignore.Until) {
+				return &ignores[i]
+			}
+		}
+	}
+	return nil
+}
+
+func RepositoryIgnores(db *gorm.DB, repositoryID uint) ([]models.RepositoryIgnore, error) {
+	ignores := []models.RepositoryIgnore{}
+	err := db.Where("repository_id = ?", repositoryID).Order("id").Find(&ignores).Error
+	return ignores, err
+}
+
+func UpdateAvailable(repo models.Repository, ignores []models.RepositoryIgnore) (bool, *models.RepositoryIgnore) {
+	if repo.CurrentVersion == "" || repo.LatestRelease == "" || repo.CurrentVersion == repo.LatestRelease {
+		return false, nil
+	}
+	if ignore := MatchIgnore(ignores, repo.LatestRelease, time.Now()); ignore != nil {
+		return false, ignore
+	}
+	return true, nil
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EcosystemGo     = "go"
+	EcosystemNpm    = "npm"
+	EcosystemPyPI   = "pypi"
+	EcosystemCargo  = "cargo"
+	EcosystemDocker = "docker"
+	EcosystemHelm   = "helm"
+)
+
+// ManifestDependency is a single dependency declared in a manifest. Source is
+// set when the manifest itself names where the dependency lives, e.g. a Cargo
+// git dependency or a Helm chart repository.
+type ManifestDependency struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	File      string `json:"file"`
+	Source    string `json:"source,omitempty"`
+}
+
+var (
+	pinnedVersionPattern = regexp.MustCompile(`^(?:=|==|\^|~)?\s*v?(\d+(?:\.\d+)*(?:[-+][0-9A-Za-z.+-]+)?)$`)
+	requirementPattern   = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	dockerFromPattern    = regexp.MustCompile(`(?i)^FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?`)
+)
+
+// ParseManifest extracts the dependencies declared in a manifest, picking the
+// format from the file name.
+func ParseManifest(filename string, content []byte) ([]ManifestDependency, error) {
+	base := strings.ToLower(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+	var deps []ManifestDependency
+	var err error
+	switch {
+	case base == "go.mod":
+		deps, err = parseGoMod(content)
+	case base == "package.json":
+		deps, err = parsePackageJSON(content)
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		deps = parseRequirements(content)
+	case base == "pyproject.toml":
+		deps, err = parsePyProject(content)
+	case base == "cargo.toml":
+		deps, err = parseCargoToml(content)
+	case base == "chart.yaml" || base == "chart.yml":
+		deps, err = parseHelmChart(content)
+	case (strings.HasPrefix(base, "docker-compose") || strings.HasPrefix(base, "compose")) &&
+		(strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml")):
+		deps, err = parseCompose(content)
+	case base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") || base == "containerfile":
+		deps = parseDockerfile(content)
+	default:
+		return nil, fmt.Errorf("Unsupported manifest %q", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %v", filename, err)
+	}
+	for i := range deps {
+		deps[i].File = filename
+	}
+	return deps, nil
+}
+
+// pinnedVersion returns the version a specifier pins to, or "" for open
+// ranges such as ">=1.0" or "*". Caret and tilde ranges count as pins to
+// their lower bound.
+func pinnedVersion(spec string) string {
+	match := pinnedVersionPattern.FindStringSubmatch(strings.TrimSpace(spec))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func parseGoMod(content []byte) ([]ManifestDependency, error) {
+	file, err := modfile.ParseLax("go.mod", content, nil)
+	if err != nil {
+		return nil, err
+	}
+	var deps []ManifestDependency
+	for _, require := range file.Require {
+		if require.Indirect {
+			continue
+		}
+		version := strings.TrimSuffix(require.Mod.Version, "+incompatible")
+		if module.IsPseudoVersion(version) {
+			version = ""
+		}
+		deps = append(deps, ManifestDependency{Ecosystem: EcosystemGo, Name: require.Mod.Path, Version: version})
+	}
+	return deps, nil
+}
+
+func parsePackageJSON(content []byte) ([]ManifestDependency, error) {
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	var deps []ManifestDependency
+	for _, group := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for _, name := range sortedKeys(group) {
+			spec := group[name]
+			dep := ManifestDependency{Ecosystem: EcosystemNpm, Name: name, Version: pinnedVersion(spec)}
+			if strings.HasPrefix(spec, "github:") || strings.Contains(spec, "github.com") {
+				dep.Source = spec
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+func parseRequirements(content []byte) []ManifestDependency {
+	var deps []ManifestDependency
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if dep, ok := parseRequirement(line); ok {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// parseRequirement reads a PEP 508 requirement such as
+// "requests[socks]==2.31.0; python_version >= '3.8'".
+func parseRequirement(requirement string) (ManifestDependency, bool) {
+	if i := strings.Index(requirement, ";"); i >= 0 {
+		requirement = requirement[:i]
+	}
+	match := requirementPattern.FindStringSubmatch(strings.TrimSpace(requirement))
+	if match == nil {
+		return ManifestDependency{}, false
+	}
+	dep := ManifestDependency{Ecosystem: EcosystemPyPI, Name: match[1]}
+	spec := strings.Trim(strings.TrimSpace(match[2]), "()")
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		for _, operator := range []string{"===", "==", "~="} {
+			if strings.HasPrefix(clause, operator) && !strings.HasSuffix(clause, ".*") {
+				dep.Version = pinnedVersion(strings.TrimPrefix(clause, operator))
+			}
+		}
+	}
+	return dep, true
+}
+
+func parsePyProject(content []byte) ([]ManifestDependency, error) {
+	var manifest struct {
+		Project struct {
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Dependencies    map[string]interface{} `toml:"dependencies"`
+				DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+				Group           map[string]struct {
+					Dependencies map[string]interface{} `toml:"dependencies"`
+				} `toml:"group"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if _, err := toml.Decode(string(content), &manifest); err != nil {
+		return nil, err
+	}
+
+	var deps []ManifestDependency
+	requirements := manifest.Project.Dependencies
+	for _, extra := range sortedKeys(manifest.Project.OptionalDependencies) {
+		requirements = append(requirements, manifest.Project.OptionalDependencies[extra]...)
+	}
+	for _, requirement := range requirements {
+		if dep, ok := parseRequirement(requirement); ok {
+			deps = append(deps, dep)
+		}
+	}
+
+	poetry := manifest.Tool.Poetry
+	tables := []map[string]interface{}{poetry.Dependencies, poetry.DevDependencies}
+	for _, group := range sortedKeys(poetry.Group) {
+		tables = append(tables, poetry.Group[group].Dependencies)
+	}
+	for _, table := range tables {
+		for _, name := range sortedKeys(table) {
+			if strings.EqualFold(name, "python") {
+				continue
+			}
+			version, source := tableDependency(table[name], "git")
+			deps = append(deps, ManifestDependency{Ecosystem: EcosystemPyPI, Name: name, Version: pinnedVersion(version), Source: source})
+		}
+	}
+	return deps, nil
+}
+
+func parseCargoToml(content []byte) ([]ManifestDependency, error) {
+	var manifest struct {
+		Dependencies      map[string]interface{} `toml:"dependencies"`
+		DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+		BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+		Workspace         struct {
+			Dependencies map[string]interface{} `toml:"dependencies"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.Decode(string(content), &manifest); err != nil {
+		return nil, err
+	}
+	var deps []ManifestDependency
+	for _, table := range []map[string]interface{}{manifest.Workspace.Dependencies, manifest.Dependencies, manifest.DevDependencies, manifest.BuildDependencies} {
+		for _, name := range sortedKeys(table) {
+			name, value := name, table[name]
+			if fields, ok := value.(map[string]interface{}); ok {
+				if _, local := fields["path"]; local {
+					continue
+				}
+				if workspace, _ := fields["workspace"].(bool); workspace {
+					continue
+				}
+				if renamed, ok := fields["package"].(string); ok {
+					name = renamed
+				}
+			}
+			version, source := tableDependency(value, "git")
+			deps = append(deps, ManifestDependency{Ecosystem: EcosystemCargo, Name: name, Version: pinnedVersion(version), Source: source})
+		}
+	}
+	return deps, nil
+}
+
+// tableDependency reads a TOML dependency that is either a version string or
+// a table with "version" and a source URL under sourceKey.
+func tableDependency(value interface{}, sourceKey string) (string, string) {
+	switch value := value.(type) {
+	case string:
+		return value, ""
+	case map[string]interface{}:
+		version, _ := value["version"].(string)
+		source, _ := value[sourceKey].(string)
+		return version, source
+	}
+	return "", ""
+}
+
+func parseCompose(content []byte) ([]ManifestDependency, error) {
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return nil, err
+	}
+	var deps []ManifestDependency
+	for _, name := range sortedKeys(compose.Services) {
+		if dep, ok := parseImageReference(compose.Services[name].Image); ok {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+func parseDockerfile(content []byte) []ManifestDependency {
+	var deps []ManifestDependency
+	stages := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		match := dockerFromPattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		if dep, ok := parseImageReference(match[1]); ok && !stages[strings.ToLower(match[1])] {
+			deps = append(deps, dep)
+		}
+		if match[2] != "" {
+			stages[strings.ToLower(match[2])] = true
+		}
+	}
+	return deps
+}
+
+// parseImageReference splits "ghcr.io/owner/app:1.2.3-alpine@sha256:..." into
+// the image name and the version of its tag. Images built from variables and
+// scratch are skipped.
+func parseImageReference(image string) (ManifestDependency, bool) {
+	image = strings.TrimSpace(image)
+	if image == "" || image == "scratch" || strings.Contains(image, "$") {
+		return ManifestDependency{}, false
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	name, tag := image, ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i+1:]
+	}
+	if i := strings.Index(tag, "-"); i > 0 {
+		tag = tag[:i]
+	}
+	return ManifestDependency{Ecosystem: EcosystemDocker, Name: name, Version: pinnedVersion(tag)}, true
+}
+
+func parseHelmChart(content []byte) ([]ManifestDependency, error) {
+	var chart struct {
+		Name         string   `yaml:"name"`
+		AppVersion   string   `yaml:"appVersion"`
+		Home         string   `yaml:"home"`
+		Sources      []string `yaml:"sources"`
+		Dependencies []struct {
+			Name       string `yaml:"name"`
+			Version    string `yaml:"version"`
+			Repository string `yaml:"repository"`
+		} `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(content, &chart); err != nil {
+		return nil, err
+	}
+	var deps []ManifestDependency
+	for _, source := range append(chart.Sources, chart.Home) {
+		if _, ok := githubRepository(source); ok && chart.AppVersion != "" {
+			deps = append(deps, ManifestDependency{Ecosystem: EcosystemHelm, Name: chart.Name, Version: pinnedVersion(chart.AppVersion), Source: source})
+			break
+		}
+	}
+	for _, dependency := range chart.Dependencies {
+		deps = append(deps, ManifestDependency{Ecosystem: EcosystemHelm, Name: dependency.Name, Version: pinnedVersion(dependency.Version), Source: dependency.Repository})
+	}
+	return deps, nil
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
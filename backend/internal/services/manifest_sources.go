@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+const manifestResolveWorkers = 8
+
+var (
+	pypiSourceLabels = []string{"source", "source code", "repository", "code", "github", "homepage", "home"}
+	manifestClient   = &http.Client{Timeout: 10 * time.Second}
+)
+
+// ResolvedDependency pairs a manifest dependency with the GitHub repository
+// ("owner/name") it is released from.
+type ResolvedDependency struct {
+	ManifestDependency
+	Repository string `json:"repository,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ResolveDependencies maps every dependency to its GitHub repository using
+// the package registries, a few at a time.
+func ResolveDependencies(deps []ManifestDependency) []ResolvedDependency {
+	resolved := make([]ResolvedDependency, len(deps))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range manifestResolveWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				resolved[i].ManifestDependency = deps[i]
+				repository, err := ResolveDependency(deps[i])
+				if err != nil {
+					resolved[i].Error = err.Error()
+					continue
+				}
+				resolved[i].Repository = repository
+			}
+		}()
+	}
+	for i := range deps {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return resolved
+}
+
+func ResolveDependency(dep ManifestDependency) (string, error) {
+	if repository, ok := githubRepository(dep.Source); ok {
+		return repository, nil
+	}
+	switch dep.Ecosystem {
+	case EcosystemGo:
+		return resolveGoModule(dep.Name)
+	case EcosystemNpm:
+		return resolveNpmPackage(dep.Name)
+	case EcosystemPyPI:
+		return resolvePyPIPackage(dep.Name)
+	case EcosystemCargo:
+		return resolveCrate(dep.Name)
+	case EcosystemDocker:
+		return resolveImage(dep.Name)
+	case EcosystemHelm:
+		if dep.Source == "" {
+			return "", errors.New("chart has no repository")
+		}
+		return "", fmt.Errorf("chart repository %s is not hosted on GitHub", dep.Source)
+	}
+	return "", fmt.Errorf("unknown ecosystem %q", dep.Ecosystem)
+}
+
+// githubRepository extracts "owner/name" from the many ways a GitHub
+// repository is written down: clone URLs, git+ssh, "github:owner/name",
+// GitHub Pages and raw content URLs.
+func githubRepository(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	raw = strings.TrimPrefix(raw, "git+")
+	if rest, ok := strings.CutPrefix(raw, "github:"); ok {
+		raw = "https://github.com/" + rest
+	}
+	if rest, ok := strings.CutPrefix(raw, "git@github.com:"); ok {
+		raw = "https://github.com/" + rest
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+	var owner, name string
+	switch {
+	case host == "github.com" || host == "www.github.com" || host == "raw.githubusercontent.com":
+		if len(segments) < 2 {
+			return "", false
+		}
+		owner, name = segments[0], segments[1]
+	case strings.HasSuffix(host, ".github.io"):
+		if len(segments) < 1 {
+			return "", false
+		}
+		owner, name = strings.TrimSuffix(host, ".github.io"), segments[0]
+	default:
+		return "", false
+	}
+	name = strings.TrimSuffix(name, ".git")
+	if owner == "" || name == "" {
+		return "", false
+	}
+	return owner + "/" + name, true
+}
+
+func resolveGoModule(modulePath string) (string, error) {
+	if repository, ok := githubRepository(modulePath); ok {
+		return repository, nil
+	}
+	segments := strings.Split(modulePath, "/")
+	switch {
+	case segments[0] == "golang.org" && len(segments) >= 3 && segments[1] == "x":
+		return "golang/" + segments[2], nil
+	case segments[0] == "gopkg.in" && len(segments) >= 2:
+		name := segments[len(segments)-1]
+		if i := strings.LastIndex(name, ".v"); i > 0 {
+			name = name[:i]
+		}
+		if len(segments) == 2 {
+			return "go-" + name + "/" + name, nil
+		}
+		return segments[1] + "/" + name, nil
+	}
+
+	// Vanity import paths are resolved through the module proxy, which
+	// records where it fetched the module from, rather than by fetching the
+	// import path itself.
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+	var latest struct {
+		Origin struct {
+			URL string `json:"URL"`
+		} `json:"Origin"`
+	}
+	if err := registryGetJSON("https://proxy.golang.org/"+escaped+"/@latest", &latest); err != nil {
+		return "", err
+	}
+	if latest.Origin.URL == "" {
+		return "", errors.New("the module proxy does not know where the module is hosted")
+	}
+	if repository, ok := githubRepository(latest.Origin.URL); ok {
+		return repository, nil
+	}
+	return "", fmt.Errorf("module is hosted at %s, not GitHub", latest.Origin.URL)
+}
+
+func resolveNpmPackage(name string) (string, error) {
+	var manifest struct {
+		Repository json.RawMessage `json:"repository"`
+		Homepage   string          `json:"homepage"`
+	}
+	if err := registryGetJSON("https://registry.npmjs.org/"+strings.Replace(name, "/", "%2F", 1)+"/latest", &manifest); err != nil {
+		return "", err
+	}
+	var repository struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(manifest.Repository, &repository.URL); err != nil {
+		json.Unmarshal(manifest.Repository, &repository)
+	}
+	for _, candidate := range []string{repository.URL, manifest.Homepage} {
+		if resolved, ok := githubRepository(candidate); ok {
+			return resolved, nil
+		}
+	}
+	if resolved, ok := npmShorthandRepository(repository.URL); ok {
+		return resolved, nil
+	}
+	return "", errors.New("package does not link a GitHub repository")
+}
+
+// npmShorthandRepository reads the bare "owner/name" form of the npm
+// repository field, which means GitHub. Prefixed forms such as
+// "gitlab:owner/name" point elsewhere; "github:" is handled by
+// githubRepository.
+func npmShorthandRepository(value string) (string, bool) {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), "#")
+	owner, name, ok := strings.Cut(value, "/")
+	if !ok || owner == "" || name == "" || strings.ContainsAny(owner, ":@") || strings.ContainsAny(name, "/:") {
+		return "", false
+	}
+	return owner + "/" + strings.TrimSuffix(name, ".git"), true
+}
+
+func resolvePyPIPackage(name string) (string, error) {
+	var project struct {
+		Info struct {
+			HomePage    string            `json:"home_page"`
+			ProjectURLs map[string]string `json:"project_urls"`
+		} `json:"info"`
+	}
+	if err := registryGetJSON("https://pypi.org/pypi/"+url.PathEscape(name)+"/json", &project); err != nil {
+		return "", err
+	}
+	candidates := make([]string, 0, len(project.Info.ProjectURLs)+1)
+	for _, label := range pypiSourceLabels {
+		for key, link := range project.Info.ProjectURLs {
+			if strings.EqualFold(key, label) {
+				candidates = append(candidates, link)
+			}
+		}
+	}
+	candidates = append(candidates, project.Info.HomePage)
+	for _, key := range sortedKeys(project.Info.ProjectURLs) {
+		candidates = append(candidates, project.Info.ProjectURLs[key])
+	}
+	for _, candidate := range candidates {
+		if resolved, ok := githubRepository(candidate); ok {
+			return resolved, nil
+		}
+	}
+	return "", errors.New("package does not link a GitHub repository")
+}
+
+func resolveCrate(name string) (string, error) {
+	var crate struct {
+		Crate struct {
+			Repository string `json:"repository"`
+			Homepage   string `json:"homepage"`
+		} `json:"crate"`
+	}
+	if err := registryGetJSON("https://crates.io/api/v1/crates/"+url.PathEscape(name), &crate); err != nil {
+		return "", err
+	}
+	for _, candidate := range []string{crate.Crate.Repository, crate.Crate.Homepage} {
+		if resolved, ok := githubRepository(candidate); ok {
+			return resolved, nil
+		}
+	}
+	return "", errors.New("crate does not link a GitHub repository")
+}
+
+// resolveImage only handles GitHub Container Registry images; registries such
+// as Docker Hub do not record where an image is built from.
+func resolveImage(image string) (string, error) {
+	if rest, ok := strings.CutPrefix(image, "ghcr.io/"); ok {
+		segments := strings.Split(rest, "/")
+		if len(segments) >= 2 {
+			return segments[0] + "/" + segments[1], nil
+		}
+	}
+	return "", errors.New("image registry does not link a source repository")
+}
+
+func registryGetJSON(target string, value interface{}) error {
+	body, err := registryGet(target)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, value); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func registryGet(target string) ([]byte, error) {
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "surveillance (release monitor)")
+	resp, err := manifestClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("not found in registry")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry request failed with status: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
@@ -0,0 +1,60 @@
+package services
+
+import "testing"
+
+func TestGitHubRepository(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"https://github.com/facebook/react", "facebook/react", true},
+		{"https://github.com/facebook/react.git", "facebook/react", true},
+		{"https://github.com/facebook/react/tree/main/packages", "facebook/react", true},
+		{"http://www.github.com/facebook/react", "facebook/react", true},
+		{"git+https://github.com/facebook/react.git", "facebook/react", true},
+		{"git+ssh://git@github.com/facebook/react.git", "facebook/react", true},
+		{"git@github.com:facebook/react.git", "facebook/react", true},
+		{"github:facebook/react", "facebook/react", true},
+		{"github.com/spf13/cobra", "spf13/cobra", true},
+		{"github.com/spf13/cobra/v2", "spf13/cobra", true},
+		{"https://raw.githubusercontent.com/owner/repo/main/README.md", "owner/repo", true},
+		{"https://psf.github.io/requests", "psf/requests", true},
+		{"https://psf.github.io", "", false},
+		{"https://github.com/facebook", "", false},
+		{"https://gitlab.com/owner/repo", "", false},
+		{"https://github.com.evil.example/owner/repo", "", false},
+		{"go.uber.org/zap", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := githubRepository(tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("githubRepository(%q) = (%q, %v), want (%q, %v)", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNpmShorthandRepository(t *testing.T) {
+	tests := []struct {
+		value, want string
+		ok          bool
+	}{
+		{"expressjs/express", "expressjs/express", true},
+		{"expressjs/express#v5", "expressjs/express", true},
+		{" owner/repo.git ", "owner/repo", true},
+		{"gitlab:owner/name", "", false},
+		{"bitbucket:owner/name", "", false},
+		{"gist:11081aaa281", "", false},
+		{"git@gitlab.com:owner/name", "", false},
+		{"owner/name/extra", "", false},
+		{"owner", "", false},
+		{"/name", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := npmShorthandRepository(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("npmShorthandRepository(%q) = (%q, %v), want (%q, %v)", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import "testing"
+
+func TestPinnedVersion(t *testing.T) {
+	tests := []struct {
+		spec, want string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"^1.2.3", "1.2.3"},
+		{"~1.2", "1.2"},
+		{"=1.0.0", "1.0.0"},
+		{"== 2.31.0", "2.31.0"},
+		{"1.0.0-beta.1", "1.0.0-beta.1"},
+		{"  4.17.21  ", "4.17.21"},
+		{">=1.0.0", ""},
+		{"<2", ""},
+		{"1.x", ""},
+		{"*", ""},
+		{"latest", ""},
+		{"1.2.3 - 2.0.0", ""},
+		{"^1.0.0 || ^2.0.0", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := pinnedVersion(tt.spec); got != tt.want {
+			t.Errorf("pinnedVersion(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		requirement   string
+		name, version string
+		ok            bool
+	}{
+		{"requests", "requests", "", true},
+		{"requests==2.31.0", "requests", "2.31.0", true},
+		{"requests [socks] == 2.31.0", "requests", "2.31.0", true},
+		{"requests[socks]==2.31.0; python_version >= '3.8'", "requests", "2.31.0", true},
+		{"Django===4.2.7", "Django", "4.2.7", true},
+		{"numpy~=1.26.0", "numpy", "1.26.0", true},
+		{"flask>=2.0,<3.0", "flask", "", true},
+		{"flask>=2.0, ==2.3.3", "flask", "2.3.3", true},
+		{"pytest==7.*", "pytest", "", true},
+		{"zope.interface (==6.1)", "zope.interface", "6.1", true},
+		{"", "", "", false},
+		{"-r other.txt", "", "", false},
+	}
+	for _, tt := range tests {
+		dep, ok := parseRequirement(tt.requirement)
+		if ok != tt.ok || dep.Name != tt.name || dep.Version != tt.version {
+			t.Errorf("parseRequirement(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.requirement, dep.Name, dep.Version, ok, tt.name, tt.version, tt.ok)
+			continue
+		}
+		if ok && dep.Ecosystem != EcosystemPyPI {
+			t.Errorf("parseRequirement(%q) ecosystem = %q, want %q", tt.requirement, dep.Ecosystem, EcosystemPyPI)
+		}
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image         string
+		name, version string
+		ok            bool
+	}{
+		{"nginx", "nginx", "", true},
+		{"nginx:1.25.3", "nginx", "1.25.3", true},
+		{"nginx:1.25.3-alpine", "nginx", "1.25.3", true},
+		{"nginx:alpine", "nginx", "", true},
+		{"nginx:latest", "nginx", "", true},
+		{"ghcr.io/owner/app:v2.1.0", "ghcr.io/owner/app", "2.1.0", true},
+		{"localhost:5000/app:1.0", "localhost:5000/app", "1.0", true},
+		{"localhost:5000/app", "localhost:5000/app", "", true},
+		{"postgres:16.1@sha256:abcdef", "postgres", "16.1", true},
+		{"redis@sha256:abcdef", "redis", "", true},
+		{"  traefik:v3.0  ", "traefik", "3.0", true},
+		{"scratch", "", "", false},
+		{"${IMAGE}:${TAG}", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		dep, ok := parseImageReference(tt.image)
+		if ok != tt.ok || dep.Name != tt.name || dep.Version != tt.version {
+			t.Errorf("parseImageReference(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.image, dep.Name, dep.Version, ok, tt.name, tt.version, tt.ok)
+		}
+	}
+}
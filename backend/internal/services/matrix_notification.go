@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+type MatrixConfig struct {
+	HomeserverURL string `json:"homeserverUrl"`
+	AccessToken   string `json:"accessToken"`
+	RoomID        string `json:"roomId"`
+}
+
+type MatrixNotifier struct {
+	httpSender
+	config    MatrixConfig
+	templates messageTemplates
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func newMatrixNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config MatrixConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Matrix config: %w", err)
+	}
+	if config.HomeserverURL == "" || config.AccessToken == "" || config.RoomID == "" {
+		return nil, errors.New("Matrix homeserver URL, access token and room ID are required")
+	}
+	config.HomeserverURL = strings.TrimSuffix(config.HomeserverURL, "/")
+	return &MatrixNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (m *MatrixNotifier) Send(notification Notification) error {
+	rendered, err := m.templates.render(notification)
+	if err != nil {
+		return err
+	}
+
+	var plain, formatted strings.Builder
+	plain.WriteString(rendered.Title + "\n\n")
+	formatted.WriteString("<h4>" + html.EscapeString(rendered.Title) + "</h4>")
+	if notification.Mention != "" {
+		plain.WriteString(notification.Mention + "\n\n")
+		formatted.WriteString("<p>" + html.EscapeString(notification.Mention) + "</p>")
+	}
+	if rendered.CustomBody {
+		plain.WriteString(rendered.Body + "\n")
+		formatted.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(rendered.Body), "\n", "<br>") + "</p>")
+	} else {
+		formatted.WriteString("<ul>")
+	}
+	for _, update := range notification.Updates {
+		if rendered.CustomBody {
+			break
+		}
+		plain.WriteString(fmt.Sprintf("- %s (%s): %s → %s\n", update.Repository, update.URL, update.PreviousVersion, update.NewVersion))
+		formatted.WriteString(fmt.Sprintf(`<li><a href="%s">%s</a>: <code>%s</code> → <code>%s</code></li>`,
+			html.EscapeString(update.URL),
+			html.EscapeString(update.Repository),
+			html.EscapeString(update.PreviousVersion),
+			html.EscapeString(update.NewVersion),
+		))
+	}
+	if !rendered.CustomBody {
+		formatted.WriteString("</ul>")
+	}
+	plain.WriteString("\n" + rendered.Footer)
+	formatted.WriteString("<p><em>" + html.EscapeString(rendered.Footer) + "</em></p>")
+
+	message := matrixMessage{
+		MsgType:       "m.text",
+		Body:          plain.String(),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.config.HomeserverURL,
+		url.PathEscape(m.config.RoomID),
+		matrixTransactionID(m.config.RoomID, notification, message.Body),
+	)
+	headers := map[string]string{"Authorization": "Bearer " + m.config.AccessToken}
+
+	return m.sendJSON("PUT", endpoint, headers, message)
+}
+
+func matrixTransactionID(roomID string, notification Notification, body string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", roomID, notification.Time.UnixNano(), body)))
+	return "surveillance-" + hex.EncodeToString(hash[:16])
+}
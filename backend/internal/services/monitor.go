@@ -2,8 +2,10 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,21 +15,60 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ScanRunStatusRunning   = "running"
+	ScanRunStatusCompleted = "completed"
+	ScanRunStatusFailed    = "failed"
+)
+
 type GitHubRelease struct {
 	TagName     string `json:"tag_name"`
 	PublishedAt string `json:"published_at"`
 	Body        string `json:"body"`
+	Draft       bool   `json:"draft"`
+	Prerelease  bool   `json:"prerelease"`
+}
+
+type GitHubError struct {
+	StatusCode     int
+	Status         string
+	RateLimitReset time.Time
+	rateLimited    bool
+}
+
+func (e *GitHubError) Error() string {
+	return "GitHub API request failed with status: " + e.Status
+}
+
+func (e *GitHubError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *GitHubError) RateLimited() bool {
+	return e.rateLimited
+}
+
+func newGitHubError(resp *http.Response) *GitHubError {
+	apiErr := &GitHubError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0") {
+		apiErr.rateLimited = true
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			apiErr.RateLimitReset = time.Unix(reset, 0)
+		}
+	}
+	return apiErr
 }
 
-func GetLatestReleaseInfo(repoName, githubToken string) (string, string, string) {
+func FetchLatestRelease(repoName, githubToken string) (GitHubRelease, error) {
+	var release GitHubRelease
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
 	req, err := http.NewRequest("GET", "https://api.github.com/repos/"+repoName+"/releases/latest", nil)
 	if err != nil {
-		utils.Logger.Warnf("Failed to create request for %s: %v", repoName, err)
-		return "", "", ""
+		return release, err
 	}
 	if githubToken != "" {
 		req.Header.Set("Authorization", "Bearer "+githubToken)
@@ -35,30 +76,33 @@ func GetLatestReleaseInfo(repoName, githubToken string) (string, string, string)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		utils.Logger.Warnf("Failed to fetch release info for %s: %v", repoName, err)
-		return "", "", ""
+		return release, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		utils.Logger.Warnf("GitHub API request for %s failed with status: %s", repoName, resp.Status)
-		return "", "", ""
+		return release, newGitHubError(resp)
 	}
 
-	var release GitHubRelease
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		utils.Logger.Warnf("Failed to decode response for %s: %v", repoName, err)
-		return "", "", ""
+		return release, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return release, nil
+}
 
-	date, _ := time.Parse(time.RFC3339, release.PublishedAt)
-	return release.TagName, date.Format("Jan 02 2006"), release.Body
+func formatReleaseDate(publishedAt string) string {
+	date, _ := time.Parse(time.RFC3339, publishedAt)
+	return date.Format("Jan 02 2006")
 }
 
 func MonitorRepositories(db *gorm.DB, githubToken, scanType string, isManual bool) error {
+	if scanType == "" {
+		scanType = "Scheduled"
+	}
 	var repos []models.Repository
 	if err := db.Find(&repos).Error; err != nil {
 		utils.Logger.Error("❌ Failed to retrieve repositories: ", err)
+		raiseScanFailed(db, scanType, err)
 		return err
 	}
 	emoji := "🔵"
@@ -67,61 +111,223 @@ func MonitorRepositories(db *gorm.DB, githubToken, scanType string, isManual boo
 	}
 	utils.Logger.Infof("%s %s scan started for %d repositories", emoji, scanType, len(repos))
 
-	var updates strings.Builder
-	var notifications []string
+	scanRun := models.ScanRun{ScanType: scanType, Status: ScanRunStatusRunning, StartedAt: time.Now()}
+	if err := db.Create(&scanRun).Error; err != nil {
+		utils.Logger.Warn("Failed to record scan run: ", err)
+	}
+
+	var updates []ReleaseUpdate
+	var scanErr error
+	threshold := FailureAlertThreshold(db)
+	cooldownDays := DefaultCooldownDays(db)
+	failures := 0
+	reachable := false
 
 	for i := range repos {
-		latestVersion, lastUpdated, changelog := GetLatestReleaseInfo(repos[i].Name, githubToken)
-		if latestVersion == "" {
+		release, upstream, found, err := FetchTrackedRelease(repos[i], githubToken)
+		if err != nil {
+			var apiErr *GitHubError
+			if errors.As(err, &apiErr) && apiErr.Unauthorized() {
+				RaiseAlert(db, AlertTokenInvalid, Alert{
+					Kind:    AlertTokenInvalid,
+					Title:   "GitHub token rejected",
+					Message: "GitHub answered 401 Unauthorized. Update or reset the API token in settings.",
+				})
+				finishScanRun(db, &scanRun, len(updates), err)
+				utils.Logger.Error("❌ Scan aborted: GitHub token is invalid")
+				return err
+			}
+			if errors.As(err, &apiErr) && apiErr.RateLimited() {
+				message := fmt.Sprintf("The GitHub API rate limit is exhausted; %d of %d repositories were not checked.", len(repos)-i, len(repos))
+				if !apiErr.RateLimitReset.IsZero() {
+					message += " It resets at " + apiErr.RateLimitReset.Format("Jan 02 3:04 PM") + "."
+				}
+				RaiseAlert(db, AlertRateLimited, Alert{Kind: AlertRateLimited, Title: "GitHub rate limit exhausted", Message: message})
+				scanErr = err
+				break
+			}
+			failures++
+			recordRepositoryFailure(db, &repos[i], err, threshold)
+			continue
+		}
+		if !reachable {
+			reachable = true
+			ResolveAlert(db, AlertTokenInvalid, "GitHub accepts the API token again.")
+			ResolveAlert(db, AlertRateLimited, "The GitHub API rate limit has reset.")
+		}
+		recordRepositorySuccess(db, &repos[i])
+		if upstream.TagName != repos[i].UpstreamLatest {
+			repos[i].UpstreamLatest = upstream.TagName
+			if err := db.Model(&repos[i]).Update("upstream_latest", upstream.TagName).Error; err != nil {
+				utils.Logger.Errorf("Failed to record upstream release for %s: %v", repos[i].Name, err)
+			}
+		}
+		if !found {
+			utils.Logger.Infof("No release of %s matches %s", repos[i].Name, repos[i].VersionConstraint)
+			continue
+		}
+
+		if holdForCooldown(db, &repos[i], release, releaseCooldown(repos[i], cooldownDays)) {
 			continue
 		}
 
+		latestVersion := release.TagName
+		lastUpdated := formatReleaseDate(release.PublishedAt)
+		changelog := release.Body
+
 		previousLatestRelease := repos[i].LatestRelease
 
-		if repos[i].LatestRelease != latestVersion {
-			if repos[i].NotifiedVersion != latestVersion {
-				updates.WriteString(fmt.Sprintf("- [%s](%s): %s → %s\n", repos[i].Name, repos[i].URL, previousLatestRelease, latestVersion))
-				notifications = append(notifications, fmt.Sprintf("- [%s](%s): %s → %s", repos[i].Name, repos[i].URL, previousLatestRelease, latestVersion))
-				repos[i].NotifiedVersion = latestVersion
+		// A release that was ignored or snoozed is looked at again on every
+		// scan and announced once nothing silences it anymore.
+		changed := repos[i].LatestRelease != latestVersion
+		if changed || repos[i].SilencedRelease == latestVersion {
+			if changed {
+				repos[i].LatestRelease = latestVersion
+				repos[i].LastUpdated = lastUpdated
+				repos[i].Changelog = changelog
+				repos[i].PendingRelease = ""
+				repos[i].PendingReleasePublishedAt = nil
+			} else if repos[i].NotifiedVersion != "" {
+				previousLatestRelease = repos[i].NotifiedVersion
 			}
 
-			repos[i].LatestRelease = latestVersion
-			repos[i].LastUpdated = lastUpdated
-			repos[i].Changelog = changelog
+			var update *ReleaseUpdate
+			ignores, err := RepositoryIgnores(db, repos[i].ID)
+			if err != nil {
+				utils.Logger.Warnf("Failed to load ignores for %s: %v", repos[i].Name, err)
+			}
+			silencedRelease := ""
+			if ignore := MatchIgnore(ignores, latestVersion, time.Now()); ignore != nil {
+				if !changed {
+					continue
+				}
+				silencedRelease = latestVersion
+				utils.Logger.Infof("🔕 %s %s is ignored (%s %s): %s", repos[i].Name, latestVersion, ignore.Type, ignore.Value, ignore.Reason)
+			} else if repos[i].NotifiedVersion != latestVersion {
+				severity, security := ClassifySeverity(previousLatestRelease, latestVersion, changelog)
+				update = &ReleaseUpdate{
+					Repository:      repos[i].Name,
+					URL:             repos[i].URL,
+					PreviousVersion: previousLatestRelease,
+					NewVersion:      latestVersion,
+					Changelog:       changelog,
+					Severity:        severity,
+					Security:        security,
+					Group:           repos[i].Group,
+				}
+			}
 
-			if err := db.Save(&repos[i]).Error; err != nil {
+			repos[i].SilencedRelease = silencedRelease
+			err = db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Save(&repos[i]).Error; err != nil {
+					return err
+				}
+				if update != nil {
+					return EnqueueUpdate(tx, repos[i], scanRun.ID, *update, scanType)
+				}
+				return nil
+			})
+			if err != nil {
 				utils.Logger.Error("❌ Failed to update repository: ", err)
+				finishScanRun(db, &scanRun, len(updates), err)
+				raiseScanFailed(db, scanType, err)
 				return err
 			}
+			if update != nil {
+				updates = append(updates, *update)
+			}
 		}
 	}
 
-	if updates.Len() > 0 {
-		formattedMsg := formatUpdates(notifications)
-		utils.Logger.Infof("🔄 Updated repositories:\n%s", formattedMsg)
-		if err := SendDiscordNotification(db, formattedMsg, scanType); err != nil {
-			utils.Logger.Errorf("Failed to send Discord notification: %v", err)
-		}
+	if scanErr == nil && len(repos) > 0 && failures == len(repos) {
+		scanErr = fmt.Errorf("all %d repositories failed to fetch", len(repos))
+	}
+
+	if len(updates) > 0 {
+		utils.Logger.Infof("🔄 Updated repositories:\n%s", formatUpdatesForLog(updates))
+		DispatchOutbox(db)
 	} else {
 		utils.Logger.Info("✅ All repositories are up to date.")
 	}
 
+	finishScanRun(db, &scanRun, len(updates), scanErr)
 	UpdateLastScanTime(db)
+	if scanErr != nil {
+		var apiErr *GitHubError
+		if !errors.As(scanErr, &apiErr) || !apiErr.RateLimited() {
+			raiseScanFailed(db, scanType, scanErr)
+		}
+		utils.Logger.Errorf("❌ %s scan failed: %v", scanType, scanErr)
+		return scanErr
+	}
+	ResolveAlert(db, AlertScanFailed, scanType+" scan completed successfully.")
 	utils.Logger.Infof("%s %s scan completed successfully", emoji, scanType)
 	return nil
 }
 
-func formatUpdates(updates []string) string {
-	return stringJoin(updates, "\n")
+func recordRepositoryFailure(db *gorm.DB, repo *models.Repository, cause error, threshold int) {
+	repo.ConsecutiveFailures++
+	repo.LastError = cause.Error()
+	utils.Logger.Warnf("Failed to fetch release info for %s (%d in a row): %v", repo.Name, repo.ConsecutiveFailures, cause)
+	if err := db.Model(repo).Updates(map[string]interface{}{
+		"consecutive_failures": repo.ConsecutiveFailures,
+		"last_error":           repo.LastError,
+	}).Error; err != nil {
+		utils.Logger.Errorf("Failed to record failure for %s: %v", repo.Name, err)
+	}
+	if repo.ConsecutiveFailures >= threshold {
+		RaiseAlert(db, repositoryAlertFingerprint(repo.ID), Alert{
+			Kind:    AlertRepositoryFailing,
+			Title:   "Repository check failing: " + repo.Name,
+			Message: fmt.Sprintf("%s has failed %d consecutive scans. Last error: %s", repo.Name, repo.ConsecutiveFailures, repo.LastError),
+		})
+	}
 }
 
-func stringJoin(items []string, sep string) string {
-	result := ""
-	for i, item := range items {
-		result += item
-		if i < len(items)-1 {
-			result += sep
-		}
+func recordRepositorySuccess(db *gorm.DB, repo *models.Repository) {
+	if repo.ConsecutiveFailures == 0 {
+		return
+	}
+	repo.ConsecutiveFailures = 0
+	repo.LastError = ""
+	if err := db.Model(repo).Updates(map[string]interface{}{
+		"consecutive_failures": 0,
+		"last_error":           "",
+	}).Error; err != nil {
+		utils.Logger.Errorf("Failed to reset failures for %s: %v", repo.Name, err)
+	}
+	ResolveAlert(db, repositoryAlertFingerprint(repo.ID), repo.Name+" is being checked successfully again.")
+}
+
+func raiseScanFailed(db *gorm.DB, scanType string, cause error) {
+	RaiseAlert(db, AlertScanFailed, Alert{
+		Kind:    AlertScanFailed,
+		Title:   scanType + " scan failed",
+		Message: cause.Error(),
+	})
+}
+
+func finishScanRun(db *gorm.DB, scanRun *models.ScanRun, updatesFound int, scanErr error) {
+	if scanRun.ID == 0 {
+		return
+	}
+	now := time.Now()
+	scanRun.FinishedAt = &now
+	scanRun.UpdatesFound = updatesFound
+	scanRun.Status = ScanRunStatusCompleted
+	if scanErr != nil {
+		scanRun.Status = ScanRunStatusFailed
+		scanRun.Error = scanErr.Error()
+	}
+	if err := db.Save(scanRun).Error; err != nil {
+		utils.Logger.Warn("Failed to record scan run: ", err)
+	}
+}
+
+func formatUpdatesForLog(updates []ReleaseUpdate) string {
+	lines := make([]string, 0, len(updates))
+	for _, update := range updates {
+		lines = append(lines, fmt.Sprintf("- [%s](%s): %s → %s", update.Repository, update.URL, update.PreviousVersion, update.NewVersion))
 	}
-	return result
+	return strings.Join(lines, "\n")
 }
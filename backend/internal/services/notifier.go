@@ -0,0 +1,274 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ChannelTypeDiscord  = "discord"
+	ChannelTypeSlack    = "slack"
+	ChannelTypeTeams    = "teams"
+	ChannelTypeTelegram = "telegram"
+	ChannelTypeMatrix   = "matrix"
+	ChannelTypeEmail    = "email"
+	ChannelTypeNtfy     = "ntfy"
+	ChannelTypeGotify   = "gotify"
+	ChannelTypePushover = "pushover"
+	ChannelTypeWebhook  = "webhook"
+)
+
+type ReleaseUpdate struct {
+	Repository      string `json:"repository"`
+	URL             string `json:"url"`
+	PreviousVersion string `json:"previousVersion"`
+	NewVersion      string `json:"newVersion"`
+	Changelog       string `json:"changelog"`
+	Severity        string `json:"severity"`
+	Security        bool   `json:"security"`
+	Group           string `json:"group,omitempty"`
+}
+
+func (u ReleaseUpdate) ReleaseURL() string {
+	return strings.TrimSuffix(u.URL, "/") + "/releases/tag/" + u.NewVersion
+}
+
+type Notification struct {
+	Updates  []ReleaseUpdate `json:"updates"`
+	ScanType string          `json:"scanType"`
+	Time     time.Time       `json:"time"`
+	Mention  string          `json:"mention,omitempty"`
+	Routed   bool            `json:"routed,omitempty"`
+	Digest   bool            `json:"digest,omitempty"`
+	Alert    *Alert          `json:"alert,omitempty"`
+}
+
+func (n Notification) HighestSeverity() string {
+	highest := SeverityUnknown
+	for _, update := range n.Updates {
+		if severityRank(update.Severity) > severityRank(highest) {
+			highest = update.Severity
+		}
+	}
+	return highest
+}
+
+type Notifier interface {
+	Send(notification Notification) error
+}
+
+var notifierFactories = map[string]func(config []byte, templates messageTemplates) (Notifier, error){
+	ChannelTypeDiscord:  newDiscordNotifier,
+	ChannelTypeSlack:    newSlackNotifier,
+	ChannelTypeTeams:    newTeamsNotifier,
+	ChannelTypeTelegram: newTelegramNotifier,
+	ChannelTypeMatrix:   newMatrixNotifier,
+	ChannelTypeEmail:    newEmailNotifier,
+	ChannelTypeNtfy:     newNtfyNotifier,
+	ChannelTypeGotify:   newGotifyNotifier,
+	ChannelTypePushover: newPushoverNotifier,
+	ChannelTypeWebhook:  newWebhookNotifier,
+}
+
+func BuildNotifier(channelType string, config []byte, templates models.MessageTemplate) (Notifier, error) {
+	factory, ok := notifierFactories[channelType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported channel type: %s", channelType)
+	}
+	compiled, err := newMessageTemplates(channelType, templates)
+	if err != nil {
+		return nil, err
+	}
+	return factory(config, compiled)
+}
+
+func NewNotifier(channel models.NotificationChannel) (Notifier, error) {
+	config, err := DecryptChannelConfig(channel)
+	if err != nil {
+		return nil, err
+	}
+	return BuildNotifier(channel.Type, config, channel.Templates)
+}
+
+func EncryptChannelConfig(config []byte) (string, error) {
+	return utils.EncryptAES(string(config))
+}
+
+func DecryptChannelConfig(channel models.NotificationChannel) ([]byte, error) {
+	if channel.Config == "" {
+		return []byte("{}"), nil
+	}
+	config, err := utils.DecryptAES(channel.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config for channel %s: %w", channel.Name, err)
+	}
+	return []byte(config), nil
+}
+
+// RedactChannelConfig hides the secrets of a decrypted channel config before
+// it leaves the API.
+func RedactChannelConfig(config []byte) []byte {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return []byte("{}")
+	}
+	redacted, err := json.Marshal(redactConfig(fields))
+	if err != nil {
+		return []byte("{}")
+	}
+	return redacted
+}
+
+// RestoreChannelSecrets keeps the stored secret for every key sent back as
+// RedactedSecret, so a channel read from the API saves unchanged.
+func RestoreChannelSecrets(config, existing []byte) ([]byte, error) {
+	var fields, existingFields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil, fmt.Errorf("invalid channel config: %w", err)
+	}
+	if err := json.Unmarshal(existing, &existingFields); err != nil {
+		return nil, err
+	}
+	if err := restoreRedacted(fields, existingFields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func SendTestNotification(db *gorm.DB, channel models.NotificationChannel) error {
+	notification := SampleNotification()
+	notifier, err := newRecordedNotifier(db, channel, deliveryContext{notification: notification})
+	if err != nil {
+		return err
+	}
+	return notifier.Send(notification)
+}
+
+// DeleteNotificationChannel removes channel, cancels its pending outbox
+// entries and drops it from the notification rules. Rules left without a
+// channel are disabled so they no longer swallow the updates they match.
+func DeleteNotificationChannel(db *gorm.DB, channel models.NotificationChannel) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&channel).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NotificationOutbox{}).
+			Where("channel_id = ? AND status = ?", channel.ID, OutboxStatusPending).
+			Update("status", OutboxStatusSuperseded).Error; err != nil {
+			return err
+		}
+		var rules []models.NotificationRule
+		if err := tx.Find(&rules).Error; err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			if !slices.Contains(rule.ChannelIDs, channel.ID) {
+				continue
+			}
+			rule.ChannelIDs = slices.DeleteFunc(rule.ChannelIDs, func(id uint) bool { return id == channel.ID })
+			if len(rule.ChannelIDs) == 0 {
+				rule.Enabled = false
+				utils.Logger.Warnf("Notification rule %s has no channels left and was disabled", rule.Name)
+			}
+			if err := tx.Save(&rule).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// withMention puts the mention on a line of its own above text, for channels
+// without a mention syntax of their own.
+func withMention(mention, text string) string {
+	if mention == "" {
+		return text
+	}
+	return mention + "\n" + text
+}
+
+func SampleNotification() Notification {
+	return Notification{
+		Updates: []ReleaseUpdate{
+			{
+				Repository:      "facebook/react",
+				URL:             "https://github.com/facebook/react",
+				PreviousVersion: "2.5.1",
+				NewVersion:      "v19.0.0",
+				Severity:        SeverityMajor,
+			},
+		},
+		ScanType: "Test",
+		Time:     time.Now(),
+	}
+}
+
+func scanTypeLabel(scanType string) string {
+	switch scanType {
+	case "Manual":
+		return "Manual Scan"
+	case "Test":
+		return "Test Scan"
+	case "Digest":
+		return "Digest"
+	case "Alert":
+		return "Operational Alert"
+	}
+	return "Scheduled Scan"
+}
+
+func clickURL(notification Notification) string {
+	if len(notification.Updates) == 1 {
+		return notification.Updates[0].ReleaseURL()
+	}
+	return ""
+}
+
+// chunkLines joins lines with separator into chunks of at most limit runes.
+// Lines longer than limit are split at rune boundaries.
+func chunkLines(lines []string, separator string, limit int) []string {
+	var pieces []string
+	for _, line := range lines {
+		pieces = append(pieces, splitRunes(line, limit)...)
+	}
+	var chunks []string
+	var current strings.Builder
+	currentLength := 0
+	for _, line := range pieces {
+		lineLength := utf8.RuneCountInString(line)
+		if currentLength > 0 && currentLength+utf8.RuneCountInString(separator)+lineLength > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentLength = 0
+		}
+		if currentLength > 0 {
+			current.WriteString(separator)
+			currentLength += utf8.RuneCountInString(separator)
+		}
+		current.WriteString(line)
+		currentLength += lineLength
+	}
+	if currentLength > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitRunes cuts text into pieces of at most limit runes.
+func splitRunes(text string, limit int) []string {
+	runes := []rune(text)
+	var pieces []string
+	for len(runes) > limit {
+		pieces = append(pieces, string(runes[:limit]))
+		runes = runes[limit:]
+	}
+	return append(pieces, string(runes))
+}
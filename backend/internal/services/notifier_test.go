@@ -0,0 +1,73 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"surveillance/internal/models"
+)
+
+func TestChunkLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		limit int
+		want  []string
+	}{
+		{"empty", nil, 10, nil},
+		{"fits", []string{"ab", "cd"}, 5, []string{"ab\ncd"}},
+		{"exact limit", []string{"abcd", "e"}, 6, []string{"abcd\ne"}},
+		{"separator overflows", []string{"abcd", "ef"}, 6, []string{"abcd", "ef"}},
+		{"keeps blank lines", []string{"a", "", "b"}, 10, []string{"a\n\nb"}},
+		{"splits long line", []string{"abcdefghij"}, 4, []string{"abcd", "efgh", "ij"}},
+		{"long line after short", []string{"x", "abcdefgh"}, 4, []string{"x", "abcd", "efgh"}},
+		{"rune boundaries", []string{"äöüßéèêë"}, 3, []string{"äöü", "ßéè", "êë"}},
+	}
+	for _, tt := range tests {
+		got := chunkLines(tt.lines, "\n", tt.limit)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: chunkLines = %q, want %q", tt.name, got, tt.want)
+		}
+		for _, chunk := range got {
+			if utf8.RuneCountInString(chunk) > tt.limit || !utf8.ValidString(chunk) {
+				t.Errorf("%s: chunk %q exceeds %d runes or is invalid UTF-8", tt.name, chunk, tt.limit)
+			}
+		}
+	}
+}
+
+func TestTelegramSplitsLongLines(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload telegramPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		texts = append(texts, payload.Text)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	notifier, err := BuildNotifier(ChannelTypeTelegram, []byte(`{"botToken":"t","chatIds":["1"],"apiBaseUrl":"`+server.URL+`"}`), models.MessageTemplate{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := strings.Repeat("v1.2.3 fixes (#123). ", 1000)
+	if err := notifier.Send(Notification{Alert: &Alert{Title: "Alert", Message: message}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) < 2 {
+		t.Fatalf("sent %d messages, want the long line split", len(texts))
+	}
+	for _, text := range texts {
+		if utf8.RuneCountInString(text) > telegramMaxMessageLength {
+			t.Errorf("message of %d runes exceeds the Telegram limit", utf8.RuneCountInString(text))
+		}
+		if strings.HasSuffix(strings.TrimSuffix(text, `\\`), `\`) {
+			t.Errorf("message ends inside an escape sequence")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type NtfyConfig struct {
+	TopicURL string `json:"topicUrl"`
+	// Priority 1-5 overrides the priority derived from the update severity,
+	// which 0 keeps.
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Token    string   `json:"token"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+}
+
+type NtfyNotifier struct {
+	httpSender
+	config    NtfyConfig
+	templates messageTemplates
+}
+
+func newNtfyNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config NtfyConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid ntfy config: %w", err)
+	}
+	if config.TopicURL == "" {
+		return nil, errors.New("ntfy topic URL is not set")
+	}
+	if config.Priority < 0 || config.Priority > 5 {
+		return nil, errors.New("ntfy priority must be between 1 and 5, or 0 to follow the update severity")
+	}
+	return &NtfyNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (n *NtfyNotifier) Send(notification Notification) error {
+	message, err := n.templates.render(notification)
+	if err != nil {
+		return err
+	}
+	priority := n.config.Priority
+	if priority == 0 {
+		priority = ntfyPriority(notification.HighestSeverity())
+	}
+	headers := map[string]string{
+		"Title":    message.Title,
+		"Priority": strconv.Itoa(priority),
+	}
+	if len(n.config.Tags) > 0 {
+		headers["Tags"] = strings.Join(n.config.Tags, ",")
+	}
+	if click := clickURL(notification); click != "" {
+		headers["Click"] = click
+	}
+	switch {
+	case n.config.Token != "":
+		headers["Authorization"] = "Bearer " + n.config.Token
+	case n.config.Username != "":
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(n.config.Username+":"+n.config.Password))
+	}
+	body := withMention(notification.Mention, message.Body) + "\n\n" + message.Footer
+	return n.send("POST", n.config.TopicURL, "text/plain; charset=utf-8", []byte(body), headers)
+}
+
+func ntfyPriority(severity string) int {
+	switch severity {
+	case SeverityPatch:
+		return 2
+	case SeverityMajor:
+		return 4
+	case SeverityCritical:
+		return 5
+	}
+	return 3
+}
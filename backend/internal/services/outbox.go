@@ -0,0 +1,288 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusDelivered  = "delivered"
+	OutboxStatusFailed     = "failed"
+	OutboxStatusSuperseded = "superseded"
+
+	outboxMaxAttempts = 10
+	outboxBaseBackoff = time.Minute
+	outboxMaxBackoff  = time.Hour
+)
+
+var dispatchMutex sync.Mutex
+
+func EnqueueUpdate(tx *gorm.DB, repo models.Repository, scanRunID uint, update ReleaseUpdate, scanType string) error {
+	routes, err := RouteUpdate(tx, repo, update)
+	if err != nil {
+		return err
+	}
+	if len(routes) == 0 {
+		utils.Logger.Infof("No notification channels route %s %s; it will not be announced.", update.Repository, update.NewVersion)
+		return nil
+	}
+
+	repositoryID := repo.ID
+	for _, route := range routes {
+		channel := route.Channel
+		channelUpdate := update
+		var pending models.NotificationOutbox
+		err := tx.Where("channel_id = ? AND repository_id = ? AND status = ?", channel.ID, repositoryID, OutboxStatusPending).
+			Order("id").First(&pending).Error
+		if err == nil {
+			var superseded ReleaseUpdate
+			if json.Unmarshal([]byte(pending.Update), &superseded) == nil {
+				channelUpdate.PreviousVersion = superseded.PreviousVersion
+				channelUpdate.Severity, channelUpdate.Security = ClassifySeverity(channelUpdate.PreviousVersion, channelUpdate.NewVersion, channelUpdate.Changelog)
+			}
+			if err := tx.Model(&models.NotificationOutbox{}).
+				Where("channel_id = ? AND repository_id = ? AND status = ?", channel.ID, repositoryID, OutboxStatusPending).
+				Update("status", OutboxStatusSuperseded).Error; err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		payload, err := json.Marshal(channelUpdate)
+		if err != nil {
+			return err
+		}
+		entry := models.NotificationOutbox{
+			ChannelID:     channel.ID,
+			RepositoryID:  repositoryID,
+			ScanRunID:     scanRunID,
+			Mention:       route.Mention,
+			Routed:        route.Routed,
+			Version:       update.NewVersion,
+			ScanType:      scanType,
+			Update:        string(payload),
+			Status:        OutboxStatusPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func CancelPendingNotifications(db *gorm.DB, repositoryID uint) error {
+	return db.Model(&models.NotificationOutbox{}).
+		Where("repository_id = ? AND status = ?", repositoryID, OutboxStatusPending).
+		Update("status", OutboxStatusSuperseded).Error
+}
+
+func DispatchOutbox(db *gorm.DB) {
+	if !dispatchMutex.TryLock() {
+		return
+	}
+	defer dispatchMutex.Unlock()
+
+	digestChannels := db.Model(&models.NotificationChannel{}).Select("id").Where("delivery_mode = ?", DeliveryModeDigest)
+	disabledChannels := db.Model(&models.NotificationChannel{}).Select("id").Where("enabled = ?", false)
+	var entries []models.NotificationOutbox
+	if err := db.Where("status = ? AND next_attempt_at <= ?", OutboxStatusPending, time.Now()).
+		Where("attempts > 0 OR held_until IS NOT NULL OR channel_id NOT IN (?)", digestChannels).
+		Where("channel_id NOT IN (?)", disabledChannels).
+		Order("id").Find(&entries).Error; err != nil {
+		utils.Logger.Errorf("Failed to load notification outbox: %v", err)
+		return
+	}
+
+	var channelIDs []uint
+	byChannel := map[uint][]models.NotificationOutbox{}
+	for _, entry := range entries {
+		if _, ok := byChannel[entry.ChannelID]; !ok {
+			channelIDs = append(channelIDs, entry.ChannelID)
+		}
+		byChannel[entry.ChannelID] = append(byChannel[entry.ChannelID], entry)
+	}
+
+	for _, channelID := range channelIDs {
+		dispatchChannel(db, channelID, byChannel[channelID])
+	}
+}
+
+func dispatchChannel(db *gorm.DB, channelID uint, entries []models.NotificationOutbox) {
+	var channel models.NotificationChannel
+	if err := db.First(&channel, channelID).Error; err != nil {
+		failOutboxEntries(db, entries, "notification channel no longer exists")
+		return
+	}
+	if !channel.Enabled {
+		return
+	}
+
+	if until, quiet := quietHoursEnd(channel.QuietHours, time.Now()); quiet {
+		var urgent, held []models.NotificationOutbox
+		for _, entry := range entries {
+			if channel.QuietHours.BypassCritical && isUrgentEntry(entry) {
+				urgent = append(urgent, entry)
+			} else {
+				held = append(held, entry)
+			}
+		}
+		holdOutboxEntries(db, held, until)
+		if len(urgent) == 0 {
+			return
+		}
+		entries = urgent
+	}
+
+	notification, err := outboxNotification(entries)
+	if err == nil && channel.DeliveryMode == DeliveryModeDigest {
+		notification.Digest = true
+		notification.ScanType = "Digest"
+	}
+	if err != nil {
+		failOutboxEntries(db, entries, err.Error())
+		return
+	}
+
+	outboxIDs := make([]uint, 0, len(entries))
+	progress := &deliveryProgress{}
+	for _, entry := range entries {
+		outboxIDs = append(outboxIDs, entry.ID)
+		progress.add(entry.SentMessages...)
+	}
+	notifier, err := newRecordedNotifier(db, channel, deliveryContext{
+		scanRunID:    entries[len(entries)-1].ScanRunID,
+		outboxIDs:    outboxIDs,
+		notification: notification,
+		progress:     progress,
+	})
+	if err == nil {
+		err = notifier.Send(notification)
+	}
+	if err != nil {
+		utils.Logger.Errorf("Failed to deliver %d update(s) to %s: %v", len(entries), channel.Name, err)
+		retryOutboxEntries(db, entries, err, progress.sent)
+		return
+	}
+
+	if err := acknowledgeOutboxEntries(db, entries); err != nil {
+		utils.Logger.Errorf("Failed to acknowledge outbox entries for %s: %v", channel.Name, err)
+		return
+	}
+	utils.Logger.Infof("Notification with %d update(s) delivered to %s channel %s.", len(entries), channel.Type, channel.Name)
+}
+
+func outboxNotification(entries []models.NotificationOutbox) (Notification, error) {
+	notification := Notification{
+		ScanType: entries[len(entries)-1].ScanType,
+		Time:     entries[0].CreatedAt,
+	}
+	var mentions []string
+	notification.Routed = true
+	for _, entry := range entries {
+		mentions = append(mentions, entry.Mention)
+		notification.Routed = notification.Routed && entry.Routed
+		if entry.CreatedAt.Before(notification.Time) {
+			notification.Time = entry.CreatedAt
+		}
+		var update ReleaseUpdate
+		if err := json.Unmarshal([]byte(entry.Update), &update); err != nil {
+			return notification, fmt.Errorf("invalid outbox payload: %w", err)
+		}
+		notification.Updates = append(notification.Updates, update)
+	}
+	notification.Mention = joinMentions(mentions...)
+	return notification, nil
+}
+
+func acknowledgeOutboxEntries(db *gorm.DB, entries []models.NotificationOutbox) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			if err := tx.Model(&models.NotificationOutbox{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+				"status":       OutboxStatusDelivered,
+				"attempts":     entry.Attempts + 1,
+				"last_error":   "",
+				"delivered_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			var repo models.Repository
+			if err := tx.First(&repo, entry.RepositoryID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if !advancesNotifiedVersion(repo, entry.Version) {
+				continue
+			}
+			if err := tx.Model(&repo).Update("notified_version", entry.Version).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// advancesNotifiedVersion reports whether delivering version moves the
+// repository forward. A resent or late retried entry for an older release
+// must not make the next scan announce the newer one again.
+func advancesNotifiedVersion(repo models.Repository, version string) bool {
+	if repo.NotifiedVersion == "" {
+		return true
+	}
+	if version == repo.NotifiedVersion {
+		return false
+	}
+	next, nextErr := semver.NewVersion(version)
+	current, currentErr := semver.NewVersion(repo.NotifiedVersion)
+	if nextErr == nil && currentErr == nil {
+		return next.GreaterThan(current)
+	}
+	return version == repo.LatestRelease
+}
+
+func retryOutboxEntries(db *gorm.DB, entries []models.NotificationOutbox, cause error, sentMessages []string) {
+	sent, err := json.Marshal(sentMessages)
+	if err != nil {
+		sent = []byte("[]")
+	}
+	for _, entry := range entries {
+		attempts := entry.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":      attempts,
+			"last_error":    cause.Error(),
+			"sent_messages": string(sent),
+		}
+		if attempts >= outboxMaxAttempts {
+			updates["status"] = OutboxStatusFailed
+		} else {
+			backoff := min(outboxBaseBackoff<<(attempts-1), outboxMaxBackoff)
+			updates["next_attempt_at"] = time.Now().Add(backoff)
+		}
+		if err := db.Model(&models.NotificationOutbox{}).Where("id = ?", entry.ID).Updates(updates).Error; err != nil {
+			utils.Logger.Errorf("Failed to update outbox entry %d: %v", entry.ID, err)
+		}
+	}
+}
+
+func failOutboxEntries(db *gorm.DB, entries []models.NotificationOutbox, reason string) {
+	for _, entry := range entries {
+		if err := db.Model(&models.NotificationOutbox{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+			"status":     OutboxStatusFailed,
+			"last_error": reason,
+		}).Error; err != nil {
+			utils.Logger.Errorf("Failed to update outbox entry %d: %v", entry.ID, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"surveillance/internal/models"
+)
+
+func TestAdvancesNotifiedVersion(t *testing.T) {
+	tests := []struct {
+		notified, latest, version string
+		want                      bool
+	}{
+		{"", "v1.2.0", "v1.2.0", true},
+		{"v1.1.0", "v1.2.0", "v1.2.0", true},
+		{"v1.2.0", "v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0", "v1.1.0", false},
+		{"1.2.0", "1.3.0", "v1.10.0", true},
+		{"v2.0.0-rc.1", "v2.0.0", "v2.0.0", true},
+		{"release-7", "release-9", "release-9", true},
+		{"release-9", "release-9", "release-7", false},
+		{"release-7", "release-9", "release-8", false},
+	}
+	for _, tt := range tests {
+		repo := models.Repository{NotifiedVersion: tt.notified, LatestRelease: tt.latest}
+		if got := advancesNotifiedVersion(repo, tt.version); got != tt.want {
+			t.Errorf("advancesNotifiedVersion(%q, latest %q, %q) = %v, want %v", tt.notified, tt.latest, tt.version, got, tt.want)
+		}
+	}
+}
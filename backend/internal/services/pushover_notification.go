@@ -0,0 +1,73 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	pushoverDefaultAPIURL    = "https://api.pushover.net/1/messages.json"
+	pushoverMaxMessageLength = 1024
+)
+
+type PushoverConfig struct {
+	UserKey  string `json:"userKey"`
+	AppToken string `json:"appToken"`
+	APIURL   string `json:"apiUrl"`
+}
+
+type PushoverNotifier struct {
+	httpSender
+	config    PushoverConfig
+	templates messageTemplates
+}
+
+func newPushoverNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config PushoverConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Pushover config: %w", err)
+	}
+	if config.UserKey == "" || config.AppToken == "" {
+		return nil, errors.New("Pushover user key and app token are required")
+	}
+	if config.APIURL == "" {
+		config.APIURL = pushoverDefaultAPIURL
+	}
+	return &PushoverNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (p *PushoverNotifier) Send(notification Notification) error {
+	rendered, err := p.templates.render(notification)
+	if err != nil {
+		return err
+	}
+	message := []rune(withMention(notification.Mention, rendered.Body))
+	if len(message) > pushoverMaxMessageLength {
+		message = append(message[:pushoverMaxMessageLength-1], '…')
+	}
+	form := url.Values{
+		"token":    {p.config.AppToken},
+		"user":     {p.config.UserKey},
+		"title":    {rendered.Title},
+		"message":  {string(message)},
+		"priority": {strconv.Itoa(pushoverPriority(notification.HighestSeverity()))},
+	}
+	if click := clickURL(notification); click != "" {
+		form.Set("url", click)
+		form.Set("url_title", "Open release")
+	}
+	return p.send("POST", p.config.APIURL, "application/x-www-form-urlencoded", []byte(form.Encode()), nil)
+}
+
+func pushoverPriority(severity string) int {
+	switch severity {
+	case SeverityPatch:
+		return -1
+	case SeverityCritical:
+		return 1
+	}
+	return 0
+}
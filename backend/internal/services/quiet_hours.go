@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const quietHoursLayout = "15:04"
+
+func ValidateQuietHours(quietHours models.QuietHours) error {
+	if !quietHours.Enabled {
+		return nil
+	}
+	start, err := time.Parse(quietHoursLayout, quietHours.Start)
+	if err != nil {
+		return fmt.Errorf("Quiet hours start must be HH:MM, got %q", quietHours.Start)
+	}
+	end, err := time.Parse(quietHoursLayout, quietHours.End)
+	if err != nil {
+		return fmt.Errorf("Quiet hours end must be HH:MM, got %q", quietHours.End)
+	}
+	if start.Equal(end) {
+		return errors.New("Quiet hours start and end must differ")
+	}
+	for _, day := range quietHours.Days {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			return fmt.Errorf("Invalid quiet hours day %d (0 = Sunday, 6 = Saturday)", day)
+		}
+	}
+	return nil
+}
+
+// quietHoursEnd reports whether now falls inside a quiet window and when that
+// window ends. Days name the weekday a window starts on, so a 22:00–07:00
+// window on Friday runs into Saturday morning.
+func quietHoursEnd(quietHours models.QuietHours, now time.Time) (time.Time, bool) {
+	if !quietHours.Enabled {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(quietHoursLayout, quietHours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(quietHoursLayout, quietHours.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for _, offset := range []int{-1, 0} {
+		day := now.AddDate(0, 0, offset)
+		if len(quietHours.Days) > 0 && !slices.Contains(quietHours.Days, int(day.Weekday())) {
+			continue
+		}
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, now.Location())
+		windowEnd := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, now.Location())
+		if !windowEnd.After(windowStart) {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		if !now.Before(windowStart) && now.Before(windowEnd) {
+			return windowEnd, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isUrgentEntry(entry models.NotificationOutbox) bool {
+	var update ReleaseUpdate
+	if err := json.Unmarshal([]byte(entry.Update), &update); err != nil {
+		return false
+	}
+	return update.Security || update.Severity == SeverityCritical
+}
+
+func holdOutboxEntries(db *gorm.DB, entries []models.NotificationOutbox, until time.Time) {
+	if len(entries) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if err := db.Model(&models.NotificationOutbox{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"held_until":      until,
+		"next_attempt_at": until,
+	}).Error; err != nil {
+		utils.Logger.Errorf("Failed to hold outbox entries: %v", err)
+		return
+	}
+	utils.Logger.Infof("🌙 Holding %d update(s) for quiet hours until %s", len(entries), until.Format("Jan 02 3:04 PM"))
+}
+
+func ReleaseHeldNotifications(db *gorm.DB, channelID uint) error {
+	return db.Model(&models.NotificationOutbox{}).
+		Where("channel_id = ? AND status = ? AND held_until IS NOT NULL", channelID, OutboxStatusPending).
+		Update("next_attempt_at", time.Now()).Error
+}
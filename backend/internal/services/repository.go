@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoMatchingRelease = errors.New("no release matches the version constraint")
+
+// AddRepository baselines repo on its tracked release and stores it. An
+// empty CurrentVersion (or "latest") means the latest release is in use.
+func AddRepository(db *gorm.DB, repo *models.Repository, githubToken string) error {
+	if err := PrepareRepository(repo, githubToken); err != nil {
+		return err
+	}
+	return db.Create(repo).Error
+}
+
+// PrepareRepository does the release lookup of AddRepository without storing
+// repo, for callers that create repositories in bulk.
+func PrepareRepository(repo *models.Repository, githubToken string) error {
+	tracked, upstream, found, err := FetchTrackedRelease(*repo, githubToken)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNoMatchingRelease
+	}
+	BaselineRelease(repo, tracked, upstream)
+	repo.CurrentVersion = MatchTagStyle(repo.CurrentVersion, repo.LatestRelease)
+	return nil
+}
+
+// MatchTagStyle writes version the way the repository tags its releases, so
+// a pinned "1.4.2" compares equal to the tag "v1.4.2".
+func MatchTagStyle(version, latestTag string) string {
+	if version == "" || version == "latest" {
+		return latestTag
+	}
+	switch {
+	case vPrefixed(latestTag) && isDigit(version[0]):
+		return "v" + version
+	case latestTag != "" && isDigit(latestTag[0]) && vPrefixed(version):
+		return version[1:]
+	}
+	return version
+}
+
+func vPrefixed(version string) bool {
+	return len(version) > 1 && version[0] == 'v' && isDigit(version[1])
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// KnownRepositories indexes the stored repositories by their lower-cased
+// GitHub "owner/name", for spotting imports that are already tracked.
+func KnownRepositories(db *gorm.DB) (map[string]models.Repository, error) {
+	var repos []models.Repository
+	if err := db.Find(&repos).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]models.Repository, len(repos))
+	for _, repo := range repos {
+		key := strings.ToLower(repo.Name)
+		if repository, ok := githubRepository(repo.URL); ok {
+			key = strings.ToLower(repository)
+		}
+		known[key] = repo
+	}
+	return known, nil
+}
+
+// DeleteRepository removes repo along with its pending notifications, alerts
+// and ignores.
+func DeleteRepository(db *gorm.DB, repo models.Repository) error {
+	if err := db.Delete(&repo).Error; err != nil {
+		return err
+	}
+	if err := CancelPendingNotifications(db, repo.ID); err != nil {
+		utils.Logger.Warn("Failed to cancel pending notifications: ", err)
+	}
+	if err := ForgetRepositoryAlerts(db, repo.ID); err != nil {
+		utils.Logger.Warn("Failed to clear repository alerts: ", err)
+	}
+	if err := db.Where("repository_id = ?", repo.ID).Delete(&models.RepositoryIgnore{}).Error; err != nil {
+		utils.Logger.Warn("Failed to delete repository ignores: ", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import "testing"
+
+func TestMatchTagStyle(t *testing.T) {
+	tests := []struct {
+		version, latestTag, want string
+	}{
+		{"", "v1.4.2", "v1.4.2"},
+		{"latest", "v1.4.2", "v1.4.2"},
+		{"1.4.2", "v1.5.0", "v1.4.2"},
+		{"v1.4.2", "v1.5.0", "v1.4.2"},
+		{"v1.4.2", "1.5.0", "1.4.2"},
+		{"1.4.2", "1.5.0", "1.4.2"},
+		{"1.4.2", "release-1.5.0", "1.4.2"},
+		{"v1.4.2", "release-1.5.0", "v1.4.2"},
+		{"version-1", "v2.0.0", "version-1"},
+		{"v", "1.0.0", "v"},
+		{"1.4.2", "v", "1.4.2"},
+		{"1.4.2", "", "1.4.2"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := MatchTagStyle(tt.version, tt.latestTag); got != tt.want {
+			t.Errorf("MatchTagStyle(%q, %q) = %q, want %q", tt.version, tt.latestTag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"surveillance/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const DefaultProvider = "github"
+
+// Route is a channel an update goes to. Routed is set when a rule picked the
+// channel, in which case the rule decides the mention alone.
+type Route struct {
+	Channel models.NotificationChannel
+	Mention string
+	Routed  bool
+}
+
+func RouteUpdate(db *gorm.DB, repo models.Repository, update ReleaseUpdate) ([]Route, error) {
+	var channels []models.NotificationChannel
+	if err := db.Where("enabled = ?", true).Order("id").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	var rules []models.NotificationRule
+	if err := db.Where("enabled = ?", true).Order("position, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	var routes []Route
+	matched := false
+	for _, rule := range rules {
+		if !RuleMatches(rule, repo, update) {
+			continue
+		}
+		matched = true
+		for _, channel := range channels {
+			if !slices.Contains(rule.ChannelIDs, channel.ID) {
+				continue
+			}
+			index := slices.IndexFunc(routes, func(route Route) bool { return route.Channel.ID == channel.ID })
+			if index == -1 {
+				routes = append(routes, Route{Channel: channel, Mention: rule.Mention, Routed: true})
+			} else if routes[index].Mention == "" {
+				routes[index].Mention = rule.Mention
+			}
+		}
+		if rule.StopProcessing {
+			break
+		}
+	}
+	if !matched {
+		for _, channel := range channels {
+			routes = append(routes, Route{Channel: channel})
+		}
+	}
+	for i := range routes {
+		routes[i].Mention = joinMentions(repo.Mention, routes[i].Mention)
+	}
+	return routes, nil
+}
+
+func joinMentions(values ...string) string {
+	var mentions []string
+	for _, value := range values {
+		for _, mention := range strings.Fields(value) {
+			if !slices.Contains(mentions, mention) {
+				mentions = append(mentions, mention)
+			}
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+func RuleMatches(rule models.NotificationRule, repo models.Repository, update ReleaseUpdate) bool {
+	if rule.RepositoryPattern != "" && !globMatch(strings.ToLower(rule.RepositoryPattern), strings.ToLower(repo.Name)) {
+		return false
+	}
+	if rule.Group != "" && !strings.EqualFold(rule.Group, repo.Group) {
+		return false
+	}
+	if rule.Tag != "" && !slices.ContainsFunc(repo.Tags, func(tag string) bool { return strings.EqualFold(tag, rule.Tag) }) {
+		return false
+	}
+	if rule.Provider != "" && !strings.EqualFold(rule.Provider, repositoryProvider(repo)) {
+		return false
+	}
+	if rule.MinSeverity != "" && severityRank(update.Severity) < severityRank(rule.MinSeverity) {
+		return false
+	}
+	if rule.SecurityOnly && !update.Security {
+		return false
+	}
+	return true
+}
+
+// globMatch matches name against a pattern where * stands for any run of
+// characters, slashes included, and ? for a single character.
+func globMatch(pattern, name string) bool {
+	p, n := 0, 0
+	star, retry := -1, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, retry = p, n
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case star != -1:
+			retry++
+			p, n = star+1, retry
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func ValidateNotificationRule(db *gorm.DB, rule models.NotificationRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return errors.New("Rule name is required")
+	}
+	if rule.MinSeverity != "" && severityRank(rule.MinSeverity) == 0 {
+		return fmt.Errorf("Unknown severity %q", rule.MinSeverity)
+	}
+	if len(rule.ChannelIDs) == 0 {
+		return errors.New("At least one channel is required")
+	}
+	var count int64
+	if err := db.Model(&models.NotificationChannel{}).Where("id IN ?", rule.ChannelIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(slices.Compact(slices.Sorted(slices.Values(rule.ChannelIDs)))) {
+		return errors.New("Unknown notification channel")
+	}
+	return nil
+}
+
+func repositoryProvider(repo models.Repository) string {
+	if repo.Provider == "" {
+		return DefaultProvider
+	}
+	return repo.Provider
+}
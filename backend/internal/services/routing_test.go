@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"surveillance/internal/models"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*", "facebook/react", true},
+		{"facebook/*", "facebook/react", true},
+		{"facebook/*", "vercel/next.js", false},
+		{"*/react", "facebook/react", true},
+		{"*react*", "facebook/react-native", true},
+		{"*-native", "facebook/react-native", true},
+		{"*-native", "facebook/react", false},
+		{"face*act", "facebook/react", true},
+		{"facebook/reac?", "facebook/react", true},
+		{"facebook/reac?", "facebook/reac", false},
+		{"facebook/react", "facebook/react", true},
+		{"facebook/react", "facebook/react-dom", false},
+		{"**", "a/b/c", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"", "facebook/react", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	repo := models.Repository{Name: "Facebook/React", Group: "Frontend", Tags: []string{"ui"}}
+	update := ReleaseUpdate{Severity: SeverityMinor}
+	tests := []struct {
+		name string
+		rule models.NotificationRule
+		want bool
+	}{
+		{"empty rule", models.NotificationRule{}, true},
+		{"pattern crosses slash", models.NotificationRule{RepositoryPattern: "*react"}, true},
+		{"pattern is case insensitive", models.NotificationRule{RepositoryPattern: "facebook/*"}, true},
+		{"pattern mismatch", models.NotificationRule{RepositoryPattern: "vercel/*"}, false},
+		{"group", models.NotificationRule{Group: "frontend"}, true},
+		{"other group", models.NotificationRule{Group: "backend"}, false},
+		{"tag", models.NotificationRule{Tag: "UI"}, true},
+		{"missing tag", models.NotificationRule{Tag: "api"}, false},
+		{"default provider", models.NotificationRule{Provider: DefaultProvider}, true},
+		{"severity below minimum", models.NotificationRule{MinSeverity: SeverityMajor}, false},
+		{"severity at minimum", models.NotificationRule{MinSeverity: SeverityMinor}, true},
+		{"security only", models.NotificationRule{SecurityOnly: true}, false},
+	}
+	for _, tt := range tests {
+		if got := RuleMatches(tt.rule, repo, update); got != tt.want {
+			t.Errorf("%s: RuleMatches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSlackMentions(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"@here", "<!here>"},
+		{"@channel @everyone", "<!channel> <!everyone>"},
+		{"U024BE7LH", "<@U024BE7LH>"},
+		{"@W012A3CDE", "<@W012A3CDE>"},
+		{"S0614TZR7", "<!subteam^S0614TZR7>"},
+		{"<@U024BE7LH>", "<@U024BE7LH>"},
+		{"@ops-team", "@ops-team"},
+		{"a&b", "a&amp;b"},
+	}
+	for _, tt := range tests {
+		if got := slackMentions(tt.text); got != tt.want {
+			t.Errorf("slackMentions(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
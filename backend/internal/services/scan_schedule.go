@@ -1,6 +1,7 @@
 package services
 
 import (
+	"surveillance/internal/models"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -18,15 +19,19 @@ func CalculateNextScan(cronExpression string) (string, error) {
 
 func GetLastAndNextScanTimes(db *gorm.DB) (lastScan, nextScan string) {
 	var settings struct {
-		LastScan     string
-		CronSchedule string
+		LastScan        string
+		CronSchedule    string
+		SchedulerPaused bool
 	}
-	db.Table("settings").Select("last_scan, cron_schedule").First(&settings)
+	db.Table("settings").Select("last_scan, cron_schedule, scheduler_paused").First(&settings)
 	if settings.LastScan == "" {
 		lastScan = "No scan performed yet"
 	} else {
 		lastScan = formatLastScan(settings.LastScan)
 	}
+	if settings.SchedulerPaused {
+		return lastScan, "Paused"
+	}
 	nextScan, err := CalculateNextScan(settings.CronSchedule)
 	if err != nil {
 		nextScan = "Error calculating next scan"
@@ -34,6 +39,18 @@ func GetLastAndNextScanTimes(db *gorm.DB) (lastScan, nextScan string) {
 	return lastScan, nextScan
 }
 
+func IsSchedulerPaused(db *gorm.DB) bool {
+	var settings models.Settings
+	if err := db.First(&settings).Error; err != nil {
+		return false
+	}
+	return settings.SchedulerPaused
+}
+
+func SetSchedulerPaused(db *gorm.DB, paused bool) error {
+	return db.Model(&models.Settings{}).Where("1 = 1").Update("scheduler_paused", paused).Error
+}
+
 func UpdateLastScanTime(db *gorm.DB) {
 	currentTime := time.Now().Format("Jan 02 2006 3:04 PM")
 	db.Exec("UPDATE settings SET last_scan = ?", currentTime)
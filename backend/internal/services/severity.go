@@ -0,0 +1,53 @@
+package services
+
+import (
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	SeverityUnknown  = "unknown"
+	SeverityPatch    = "patch"
+	SeverityMinor    = "minor"
+	SeverityMajor    = "major"
+	SeverityCritical = "critical"
+)
+
+var securityPattern = regexp.MustCompile(`(?i)\b(security|vulnerabilit(y|ies)|CVE-\d{4}-\d+|GHSA(-[a-z0-9]{4}){3})\b`)
+
+func ClassifySeverity(previousVersion, newVersion, changelog string) (string, bool) {
+	security := securityPattern.MatchString(changelog)
+	if security {
+		return SeverityCritical, true
+	}
+	previous, err := semver.NewVersion(previousVersion)
+	if err != nil {
+		return SeverityUnknown, false
+	}
+	next, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return SeverityUnknown, false
+	}
+	switch {
+	case next.Major() != previous.Major():
+		return SeverityMajor, false
+	case next.Minor() != previous.Minor():
+		return SeverityMinor, false
+	}
+	return SeverityPatch, false
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityPatch:
+		return 1
+	case SeverityMinor:
+		return 2
+	case SeverityMajor:
+		return 3
+	case SeverityCritical:
+		return 4
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	slackMaxSectionsPerMessage = 48
+	slackMaxSectionLength      = 3000
+	slackMaxHeaderLength       = 150
+)
+
+var (
+	slackMemberPattern = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+	slackGroupPattern  = regexp.MustCompile(`^S[A-Z0-9]{6,}$`)
+)
+
+type SlackConfig struct {
+	WebhookURL string `json:"webhookUrl"`
+}
+
+type SlackNotifier struct {
+	httpSender
+	config    SlackConfig
+	templates messageTemplates
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type      string       `json:"type"`
+	Text      *slackText   `json:"text,omitempty"`
+	Accessory *slackButton `json:"accessory,omitempty"`
+	Elements  []slackText  `json:"elements,omitempty"`
+}
+
+type slackButton struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func newSlackNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config SlackConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Slack config: %w", err)
+	}
+	if config.WebhookURL == "" {
+		return nil, errors.New("Slack webhook URL is not set")
+	}
+	return &SlackNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (s *SlackNotifier) Send(notification Notification) error {
+	message, err := s.templates.render(notification)
+	if err != nil {
+		return err
+	}
+
+	var sections []slackBlock
+	if message.CustomBody {
+		for _, text := range chunkLines(strings.Split(message.Body, "\n"), "\n", slackMaxSectionLength) {
+			sections = append(sections, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+		}
+	}
+	for _, update := range notification.Updates {
+		if message.CustomBody {
+			break
+		}
+		sections = append(sections, slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*<%s|%s>*\n`%s` → `%s`", update.URL, slackEscape(update.Repository), slackEscape(update.PreviousVersion), slackEscape(update.NewVersion)),
+			},
+			Accessory: &slackButton{
+				Type: "button",
+				Text: slackText{Type: "plain_text", Text: "View release"},
+				URL:  update.ReleaseURL(),
+			},
+		})
+	}
+
+	text := message.Title
+	if mention := slackMentions(notification.Mention); mention != "" && len(sections) > 0 {
+		sections[0].Text.Text = mention + "\n" + sections[0].Text.Text
+		text = mention + " " + text
+	}
+
+	for start := 0; start == 0 || start < len(sections); start += slackMaxSectionsPerMessage {
+		end := min(start+slackMaxSectionsPerMessage, len(sections))
+		var blocks []slackBlock
+		if start == 0 {
+			blocks = append(blocks, slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: truncateRunes(message.Title, slackMaxHeaderLength)}})
+		}
+		blocks = append(blocks, sections[start:end]...)
+		if end == len(sections) {
+			blocks = append(blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(message.Footer)}}})
+		}
+		if err := s.postJSON(s.config.WebhookURL, slackPayload{Text: text, Blocks: blocks}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slackMentions writes mentions the way Slack expects them: @here, @channel
+// and @everyone become special mentions and member (U…, W…) or user group
+// (S…) IDs become links. Anything else is kept as escaped text.
+func slackMentions(text string) string {
+	var mentions []string
+	for _, field := range strings.Fields(text) {
+		id := strings.TrimPrefix(field, "@")
+		switch {
+		case strings.HasPrefix(field, "<") && strings.HasSuffix(field, ">"):
+			mentions = append(mentions, field)
+		case id == "here" || id == "channel" || id == "everyone":
+			mentions = append(mentions, "<!"+id+">")
+		case slackMemberPattern.MatchString(id):
+			mentions = append(mentions, "<@"+id+">")
+		case slackGroupPattern.MatchString(id):
+			mentions = append(mentions, "<!subteam^"+id+">")
+		default:
+			mentions = append(mentions, slackEscape(field))
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
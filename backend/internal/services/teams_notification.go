@@ -0,0 +1,118 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type TeamsConfig struct {
+	WebhookURL string `json:"webhookUrl"`
+}
+
+type TeamsNotifier struct {
+	httpSender
+	config    TeamsConfig
+	templates messageTemplates
+}
+
+type teamsElement map[string]interface{}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	ContentURL  *string      `json:"contentUrl"`
+	Content     teamsElement `json:"content"`
+}
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+func newTeamsNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config TeamsConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Teams config: %w", err)
+	}
+	if config.WebhookURL == "" {
+		return nil, errors.New("Teams webhook URL is not set")
+	}
+	return &TeamsNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (t *TeamsNotifier) Send(notification Notification) error {
+	message, err := t.templates.render(notification)
+	if err != nil {
+		return err
+	}
+
+	body := []teamsElement{
+		{
+			"type":   "TextBlock",
+			"text":   message.Title,
+			"size":   "Large",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+	}
+	if notification.Mention != "" {
+		body = append(body, teamsElement{"type": "TextBlock", "text": notification.Mention, "wrap": true})
+	}
+	if message.CustomBody {
+		body = append(body, teamsElement{"type": "TextBlock", "text": message.Body, "wrap": true})
+	}
+	for _, update := range notification.Updates {
+		if message.CustomBody {
+			break
+		}
+		body = append(body, teamsElement{
+			"type":      "Container",
+			"separator": true,
+			"items": []teamsElement{
+				{
+					"type":   "TextBlock",
+					"text":   fmt.Sprintf("[%s](%s)", update.Repository, update.URL),
+					"weight": "Bolder",
+					"wrap":   true,
+				},
+				{
+					"type": "FactSet",
+					"facts": []teamsElement{
+						{"title": "Previous version", "value": update.PreviousVersion},
+						{"title": "New version", "value": update.NewVersion},
+					},
+				},
+				{
+					"type": "ActionSet",
+					"actions": []teamsElement{
+						{"type": "Action.OpenUrl", "title": "Open release", "url": update.ReleaseURL()},
+					},
+				},
+			},
+		})
+	}
+	body = append(body, teamsElement{
+		"type":     "TextBlock",
+		"text":     message.Footer,
+		"isSubtle": true,
+		"size":     "Small",
+		"wrap":     true,
+	})
+
+	payload := teamsPayload{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: teamsElement{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+					"msteams": teamsElement{"width": "Full"},
+				},
+			},
+		},
+	}
+	return t.postJSON(t.config.WebhookURL, payload)
+}
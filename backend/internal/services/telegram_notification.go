@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	telegramDefaultAPIBaseURL = "https://api.telegram.org"
+	telegramMaxMessageLength  = 4096
+)
+
+var (
+	telegramEscaper     = strings.NewReplacer("\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!")
+	telegramURLEscaper  = strings.NewReplacer("\\", "\\\\", ")", "\\)")
+	telegramCodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+)
+
+type TelegramConfig struct {
+	BotToken   string   `json:"botToken"`
+	ChatIDs    []string `json:"chatIds"`
+	APIBaseURL string   `json:"apiBaseUrl"`
+}
+
+type TelegramNotifier struct {
+	httpSender
+	config    TelegramConfig
+	templates messageTemplates
+}
+
+type telegramPayload struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+func newTelegramNotifier(raw []byte, templates messageTemplates) (Notifier, error) {
+	var config TelegramConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid Telegram config: %w", err)
+	}
+	if config.BotToken == "" {
+		return nil, errors.New("Telegram bot token is not set")
+	}
+	if len(config.ChatIDs) == 0 {
+		return nil, errors.New("at least one Telegram chat ID is required")
+	}
+	if config.APIBaseURL == "" {
+		config.APIBaseURL = telegramDefaultAPIBaseURL
+	}
+	config.APIBaseURL = strings.TrimSuffix(config.APIBaseURL, "/")
+	return &TelegramNotifier{httpSender: newHTTPSender(), config: config, templates: templates}, nil
+}
+
+func (t *TelegramNotifier) Send(notification Notification) error {
+	message, err := t.templates.render(notification)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{"*" + telegramEscape(message.Title) + "*", ""}
+	if notification.Mention != "" {
+		lines = append(lines, telegramEscape(notification.Mention), "")
+	}
+	if message.CustomBody {
+		for _, line := range strings.Split(message.Body, "\n") {
+			// Escaping at most doubles a line, so splitting it first keeps
+			// chunkLines from cutting an escape sequence in two.
+			for _, piece := range splitRunes(line, telegramMaxMessageLength/2) {
+				lines = append(lines, telegramEscape(piece))
+			}
+		}
+	}
+	for _, update := range notification.Updates {
+		if message.CustomBody {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• [%s](%s): `%s` → `%s`",
+			telegramEscape(update.Repository),
+			telegramURLEscaper.Replace(update.URL),
+			telegramCodeEscaper.Replace(update.PreviousVersion),
+			telegramCodeEscaper.Replace(update.NewVersion),
+		))
+	}
+	lines = append(lines, "", "_"+telegramEscape(message.Footer)+"_")
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.config.APIBaseURL, t.config.BotToken)
+	for _, chatID := range t.config.ChatIDs {
+		for _, text := range chunkLines(lines, "\n", telegramMaxMessageLength) {
+			payload := telegramPayload{
+				ChatID:                chatID,
+				Text:                  text,
+				ParseMode:             "MarkdownV2",
+				DisableWebPagePreview: true,
+			}
+			if err := t.postJSON(url, payload); err != nil {
+				return fmt.Errorf("chat %s: %w", chatID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func telegramEscape(text string) string {
+	return telegramEscaper.Replace(text)
+}
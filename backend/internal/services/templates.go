@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"surveillance/internal/models"
+	"text/template"
+	"time"
+)
+
+const (
+	markdownUpdatesTemplate = "{{range .Updates}}- [{{.Repository}}]({{.URL}}): {{.PreviousVersion}} → {{.NewVersion}}\n{{end}}"
+	plainUpdatesTemplate    = "{{range .Updates}}{{.Repository}}: {{.PreviousVersion}} → {{.NewVersion}}\n{{end}}"
+	markdownDigestTemplate  = "{{range .Groups}}**{{.Name}} · {{.Severity}}**\n{{range .Updates}}- [{{.Repository}}]({{.URL}}): {{.PreviousVersion}} → {{.NewVersion}}\n{{end}}\n{{end}}"
+	plainDigestTemplate     = "{{range .Groups}}{{.Name}} · {{.Severity}}\n{{range .Updates}}{{.Repository}}: {{.PreviousVersion}} → {{.NewVersion}}\n{{end}}\n{{end}}"
+	digestTitle             = "Repository Update Digest"
+)
+
+var defaultMessageTemplate = models.MessageTemplate{
+	Title:  "Repository Updates Available",
+	Body:   plainUpdatesTemplate,
+	Footer: `{{.ScanTypeLabel}} • {{.Time.Format "Today at 3:04 PM"}}`,
+}
+
+var channelTemplateDefaults = map[string]models.MessageTemplate{
+	ChannelTypeDiscord: {Body: markdownUpdatesTemplate},
+	ChannelTypeGotify:  {Body: markdownUpdatesTemplate},
+	ChannelTypeEmail:   {Footer: `{{.ScanTypeLabel}} • {{.Time.Format "Jan 02 2006 3:04 PM"}}`},
+}
+
+var digestBodyDefaults = map[string]string{
+	ChannelTypeDiscord: markdownDigestTemplate,
+	ChannelTypeGotify:  markdownDigestTemplate,
+}
+
+var messageTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+type Message struct {
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Footer     string `json:"footer"`
+	CustomBody bool   `json:"-"`
+}
+
+type messageTemplateData struct {
+	Updates       []ReleaseUpdate
+	ScanType      string
+	ScanTypeLabel string
+	Time          time.Time
+	Timestamp     string
+	Mention       string
+	Groups        []UpdateGroup
+}
+
+type messageTemplates struct {
+	title, body, footer     *template.Template
+	digestTitle, digestBody *template.Template
+	customBody              bool
+}
+
+func newMessageTemplates(channelType string, custom models.MessageTemplate) (messageTemplates, error) {
+	resolved := defaultMessageTemplate
+	defaults := channelTemplateDefaults[channelType]
+	for _, layer := range []models.MessageTemplate{defaults, custom} {
+		if layer.Title != "" {
+			resolved.Title = layer.Title
+		}
+		if layer.Body != "" {
+			resolved.Body = layer.Body
+		}
+		if layer.Footer != "" {
+			resolved.Footer = layer.Footer
+		}
+	}
+
+	var templates messageTemplates
+	var err error
+	if templates.title, err = parseMessageTemplate("title", resolved.Title); err != nil {
+		return templates, err
+	}
+	if templates.body, err = parseMessageTemplate("body", resolved.Body); err != nil {
+		return templates, err
+	}
+	if templates.footer, err = parseMessageTemplate("footer", resolved.Footer); err != nil {
+		return templates, err
+	}
+	digest := models.MessageTemplate{Title: resolved.Title, Body: resolved.Body}
+	if custom.Title == "" {
+		digest.Title = digestTitle
+	}
+	if custom.Body == "" {
+		digest.Body = plainDigestTemplate
+		if body, ok := digestBodyDefaults[channelType]; ok {
+			digest.Body = body
+		}
+	}
+	if templates.digestTitle, err = parseMessageTemplate("title", digest.Title); err != nil {
+		return templates, err
+	}
+	if templates.digestBody, err = parseMessageTemplate("body", digest.Body); err != nil {
+		return templates, err
+	}
+	templates.customBody = custom.Body != ""
+	return templates, nil
+}
+
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(messageTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func (t messageTemplates) render(notification Notification) (Message, error) {
+	data := messageTemplateData{
+		Updates:       notification.Updates,
+		ScanType:      notification.ScanType,
+		ScanTypeLabel: scanTypeLabel(notification.ScanType),
+		Time:          notification.Time,
+		Timestamp:     notification.Time.Format(time.RFC3339),
+		Mention:       notification.Mention,
+		Groups:        groupUpdates(notification.Updates),
+	}
+	title, body := t.title, t.body
+	if notification.Digest {
+		title, body = t.digestTitle, t.digestBody
+	}
+	message := Message{CustomBody: t.customBody || notification.Digest}
+	if notification.Alert != nil {
+		title, body = nil, nil
+		message = Message{Title: notification.Alert.Title, Body: notification.Alert.Message, CustomBody: true}
+	}
+	for _, part := range []struct {
+		tmpl   *template.Template
+		target *string
+	}{
+		{title, &message.Title},
+		{body, &message.Body},
+		{t.footer, &message.Footer},
+	} {
+		if part.tmpl == nil {
+			continue
+		}
+		var out bytes.Buffer
+		if err := part.tmpl.Execute(&out, data); err != nil {
+			return message, fmt.Errorf("failed to render %s template: %w", part.tmpl.Name(), err)
+		}
+		*part.target = strings.TrimSpace(out.String())
+	}
+	return message, nil
+}
+
+// previewConfigs are placeholder channel configs, enough for each notifier
+// to build the payloads a preview shows.
+var previewConfigs = map[string]string{
+	ChannelTypeDiscord:  `{"webhookUrl": "https://discord.com/api/webhooks/preview"}`,
+	ChannelTypeSlack:    `{"webhookUrl": "https://hooks.slack.com/services/preview"}`,
+	ChannelTypeTeams:    `{"webhookUrl": "https://example.webhook.office.com/preview"}`,
+	ChannelTypeTelegram: `{"botToken": "preview", "chatIds": ["preview"]}`,
+	ChannelTypeMatrix:   `{"homeserverUrl": "https://matrix.example.com", "accessToken": "preview", "roomId": "!preview:example.com"}`,
+	ChannelTypeEmail:    `{"host": "smtp.example.com", "from": "Surveillance <surveillance@example.com>", "to": ["you@example.com"]}`,
+	ChannelTypeNtfy:     `{"topicUrl": "https://ntfy.sh/preview"}`,
+	ChannelTypeGotify:   `{"serverUrl": "https://gotify.example.com", "appToken": "preview"}`,
+	ChannelTypePushover: `{"userKey": "preview", "appToken": "preview"}`,
+	ChannelTypeWebhook:  `{"url": "https://example.com/webhook", "secret": "preview"}`,
+}
+
+type NotificationPreview struct {
+	Message
+	Payloads []PayloadPreview `json:"payloads"`
+}
+
+type PayloadPreview struct {
+	Method      string      `json:"method"`
+	ContentType string      `json:"contentType"`
+	Body        interface{} `json:"body"`
+}
+
+type payloadCapturer interface {
+	capturePayloads(capture func(PayloadPreview))
+}
+
+// PreviewMessage renders the sample notification with custom templates and
+// captures the payloads the channel type would send for it, without sending.
+func PreviewMessage(channelType string, custom models.MessageTemplate) (NotificationPreview, error) {
+	factory, ok := notifierFactories[channelType]
+	if !ok {
+		return NotificationPreview{}, fmt.Errorf("unsupported channel type: %s", channelType)
+	}
+	templates, err := newMessageTemplates(channelType, custom)
+	if err != nil {
+		return NotificationPreview{}, err
+	}
+	notification := SampleNotification()
+	message, err := templates.render(notification)
+	if err != nil {
+		return NotificationPreview{}, err
+	}
+	notifier, err := factory([]byte(previewConfigs[channelType]), templates)
+	if err != nil {
+		return NotificationPreview{}, err
+	}
+	preview := NotificationPreview{Message: message, Payloads: []PayloadPreview{}}
+	if capturer, ok := notifier.(payloadCapturer); ok {
+		capturer.capturePayloads(func(payload PayloadPreview) {
+			preview.Payloads = append(preview.Payloads, payload)
+		})
+		if err := notifier.Send(notification); err != nil {
+			return preview, err
+		}
+	}
+	return preview, nil
+}
+
+func previewBody(contentType string, body []byte) interface{} {
+	if strings.Contains(contentType, "json") && json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	return string(body)
+}
@@ -0,0 +1,701 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"surveillance/internal/models"
+	"surveillance/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WatchlistVersion = 1
+	RedactedSecret   = "<redacted>"
+)
+
+const (
+	ImportModeMerge   = "merge"
+	ImportModeReplace = "replace"
+
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionDelete    = "delete"
+	ImportActionKeep      = "keep"
+	ImportActionConflict  = "conflict"
+	ImportActionFailed    = "failed"
+)
+
+var secretConfigKeys = map[string]bool{
+	"webhookUrl":  true,
+	"password":    true,
+	"token":       true,
+	"appToken":    true,
+	"accessToken": true,
+	"botToken":    true,
+	"userKey":     true,
+	"secret":      true,
+	"headers":     true,
+}
+
+// Watchlist is the document served by GET /api/export and accepted by
+// POST /api/import, as JSON or YAML:
+//
+//	version: 1
+//	exportedAt: 2025-01-01T12:00:00Z
+//	repositories:
+//	  - name: facebook/react
+//	    url: https://github.com/facebook/react
+//	    currentVersion: v18.3.1
+//	    group: frontend
+//	    tags: [ui]
+//	    provider: github
+//	    mention: "@here"
+//	    cooldownDays: 3
+//	    versionConstraint: ^18
+//	    ignores:
+//	      - type: tag
+//	        value: v19.0.0-rc.1
+//	        reason: waiting for stable
+//	channels:
+//	  - name: Team Discord
+//	    type: discord
+//	    enabled: true
+//	    config:
+//	      webhookUrl: <redacted>
+//	    deliveryMode: immediate
+//
+// Repositories are identified by url and channels by name and type; only
+// those fields are required. An imported repository replaces the settings of
+// the stored one, keeping its current version when currentVersion is
+// omitted. channels is only exported on request, with secret config values
+// replaced by "<redacted>" unless secrets are included too. Importing a
+// redacted value keeps the secret of the existing channel.
+type Watchlist struct {
+	Version      int                   `json:"version" yaml:"version"`
+	ExportedAt   time.Time             `json:"exportedAt" yaml:"exportedAt"`
+	Repositories []WatchlistRepository `json:"repositories" yaml:"repositories"`
+	Channels     []WatchlistChannel    `json:"channels,omitempty" yaml:"channels,omitempty"`
+}
+
+type WatchlistRepository struct {
+	Name              string            `json:"name" yaml:"name"`
+	URL               string            `json:"url" yaml:"url"`
+	CurrentVersion    string            `json:"currentVersion,omitempty" yaml:"currentVersion,omitempty"`
+	Group             string            `json:"group,omitempty" yaml:"group,omitempty"`
+	Tags              []string          `json:"tags,omitempty" yaml:"tags,flow,omitempty"`
+	Provider          string            `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Mention           string            `json:"mention,omitempty" yaml:"mention,omitempty"`
+	CooldownDays      *int              `json:"cooldownDays,omitempty" yaml:"cooldownDays,omitempty"`
+	VersionConstraint string            `json:"versionConstraint,omitempty" yaml:"versionConstraint,omitempty"`
+	Ignores           []WatchlistIgnore `json:"ignores,omitempty" yaml:"ignores,omitempty"`
+}
+
+type WatchlistIgnore struct {
+	Type   string     `json:"type" yaml:"type"`
+	Value  string     `json:"value,omitempty" yaml:"value,omitempty"`
+	Until  *time.Time `json:"until,omitempty" yaml:"until,omitempty"`
+	Reason string     `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+type WatchlistChannel struct {
+	Name              string                 `json:"name" yaml:"name"`
+	Type              string                 `json:"type" yaml:"type"`
+	Enabled           *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Config            map[string]interface{} `json:"config" yaml:"config"`
+	Templates         models.MessageTemplate `json:"templates" yaml:"templates,omitempty"`
+	DeliveryMode      string                 `json:"deliveryMode,omitempty" yaml:"deliveryMode,omitempty"`
+	DigestSchedule    string                 `json:"digestSchedule,omitempty" yaml:"digestSchedule,omitempty"`
+	QuietHours        models.QuietHours      `json:"quietHours" yaml:"quietHours,omitempty"`
+	OperationalAlerts bool                   `json:"operationalAlerts,omitempty" yaml:"operationalAlerts,omitempty"`
+}
+
+type ImportOptions struct {
+	Mode          string
+	DryRun        bool
+	SkipConflicts bool
+	Confirm       bool
+}
+
+type ImportChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type ImportResult struct {
+	Name    string                  `json:"name"`
+	URL     string                  `json:"url,omitempty"`
+	Type    string                  `json:"type,omitempty"`
+	ID      uint                    `json:"id,omitempty"`
+	Action  string                  `json:"action"`
+	Changes map[string]ImportChange `json:"changes,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Mode         string         `json:"mode"`
+	DryRun       bool           `json:"dryRun"`
+	Repositories []ImportResult `json:"repositories"`
+	Channels     []ImportResult `json:"channels"`
+	Summary      map[string]int `json:"summary"`
+	Warning      string         `json:"warning,omitempty"`
+}
+
+func ExportWatchlist(db *gorm.DB, includeChannels, includeSecrets bool) (Watchlist, error) {
+	watchlist := Watchlist{Version: WatchlistVersion, ExportedAt: time.Now().UTC(), Repositories: []WatchlistRepository{}}
+	var repos []models.Repository
+	if err := db.Order("name, id").Find(&repos).Error; err != nil {
+		return watchlist, err
+	}
+	ignores, err := ignoresByRepository(db)
+	if err != nil {
+		return watchlist, err
+	}
+	for _, repo := range repos {
+		watchlist.Repositories = append(watchlist.Repositories, watchlistRepository(repo, ignores[repo.ID]))
+	}
+
+	if !includeChannels {
+		return watchlist, nil
+	}
+	var channels []models.NotificationChannel
+	if err := db.Order("id").Find(&channels).Error; err != nil {
+		return watchlist, err
+	}
+	for _, channel := range channels {
+		exported, err := watchlistChannel(channel)
+		if err != nil {
+			utils.Logger.Warnf("Exporting %s without its config: %v", channel.Name, err)
+		}
+		if !includeSecrets {
+			exported.Config = redactConfig(exported.Config)
+		}
+		watchlist.Channels = append(watchlist.Channels, exported)
+	}
+	return watchlist, nil
+}
+
+// ImportWatchlist applies watchlist on top of the stored repositories and
+// channels, or only reports what it would change when options.DryRun is set.
+// Each entry succeeds or fails on its own. Replace mode also deletes the
+// repositories and channels the document leaves out, but only for sections
+// it contains and only when options.Confirm is set; otherwise they are kept.
+func ImportWatchlist(db *gorm.DB, watchlist Watchlist, options ImportOptions, githubToken string) (ImportReport, error) {
+	if watchlist.Version > WatchlistVersion {
+		return ImportReport{}, fmt.Errorf("Unsupported watchlist version %d", watchlist.Version)
+	}
+	report := ImportReport{Mode: options.Mode, DryRun: options.DryRun, Repositories: []ImportResult{}, Channels: []ImportResult{}, Summary: map[string]int{}}
+	if err := importRepositories(db, watchlist.Repositories, options, githubToken, &report); err != nil {
+		return report, err
+	}
+	if watchlist.Channels != nil {
+		if err := importChannels(db, watchlist.Channels, options, &report); err != nil {
+			return report, err
+		}
+	}
+	for _, results := range [][]ImportResult{report.Repositories, report.Channels} {
+		for _, result := range results {
+			report.Summary[result.Action]++
+		}
+	}
+	if deletes := report.Summary[ImportActionDelete]; deletes > 0 && options.DryRun {
+		report.Warning = fmt.Sprintf("Replace mode will delete %d entries missing from the watchlist once the import is confirmed", deletes)
+	} else if kept := report.Summary[ImportActionKeep]; kept > 0 {
+		report.Warning = fmt.Sprintf("Kept %d entries missing from the watchlist; confirm the import to delete them", kept)
+	}
+	return report, nil
+}
+
+func importRepositories(db *gorm.DB, entries []WatchlistRepository, options ImportOptions, githubToken string, report *ImportReport) error {
+	var repos []models.Repository
+	if err := db.Find(&repos).Error; err != nil {
+		return err
+	}
+	ignores, err := ignoresByRepository(db)
+	if err != nil {
+		return err
+	}
+	existing := map[string]models.Repository{}
+	for _, repo := range repos {
+		existing[repositoryKey(repo.URL)] = repo
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry, err := normalizeWatchlistRepository(entry)
+		result := ImportResult{Name: entry.Name, URL: entry.URL}
+		key := repositoryKey(entry.URL)
+		repo, exists := existing[key]
+		switch {
+		case err != nil:
+			result.Action, result.Error = ImportActionFailed, err.Error()
+		case seen[key]:
+			result.Action, result.Error = ImportActionConflict, "URL is listed more than once"
+		case exists:
+			result.ID = repo.ID
+			if entry.CurrentVersion == "" {
+				entry.CurrentVersion = repo.CurrentVersion
+			}
+			entry.CurrentVersion = MatchTagStyle(entry.CurrentVersion, repo.LatestRelease)
+			result.Changes = diffFields(watchlistRepository(repo, ignores[repo.ID]), entry)
+			switch {
+			case len(result.Changes) == 0:
+				result.Action = ImportActionUnchanged
+			case options.SkipConflicts:
+				result.Action, result.Error = ImportActionConflict, "A repository with this URL already exists"
+			default:
+				result.Action = ImportActionUpdate
+				if !options.DryRun {
+					if err := updateImportedRepository(db, &repo, entry, githubToken); err != nil {
+						result.Action, result.Error = ImportActionFailed, err.Error()
+					}
+				}
+			}
+		default:
+			result.Action = ImportActionCreate
+			if !options.DryRun {
+				repo, err := createImportedRepository(db, entry, githubToken)
+				result.ID = repo.ID
+				switch {
+				case errors.Is(err, gorm.ErrDuplicatedKey) || err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed"):
+					result.Action, result.Error = ImportActionConflict, "A repository with this URL already exists"
+				case err != nil:
+					result.Action, result.Error = ImportActionFailed, err.Error()
+				}
+			}
+		}
+		if entry.URL != "" {
+			seen[key] = true
+		}
+		report.Repositories = append(report.Repositories, result)
+	}
+
+	if options.Mode != ImportModeReplace || entries == nil {
+		return nil
+	}
+	for _, repo := range repos {
+		if seen[repositoryKey(repo.URL)] {
+			continue
+		}
+		result := ImportResult{Name: repo.Name, URL: repo.URL, ID: repo.ID, Action: ImportActionDelete}
+		if !options.DryRun && !options.Confirm {
+			result.Action = ImportActionKeep
+		} else if !options.DryRun {
+			if err := DeleteRepository(db, repo); err != nil {
+				result.Action, result.Error = ImportActionFailed, err.Error()
+			}
+		}
+		report.Repositories = append(report.Repositories, result)
+	}
+	return nil
+}
+
+func normalizeWatchlistRepository(entry WatchlistRepository) (WatchlistRepository, error) {
+	entry.URL = strings.TrimSuffix(strings.TrimSpace(entry.URL), "/")
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.Name == "" {
+		entry.Name, _ = githubRepository(entry.URL)
+	}
+	if entry.URL == "" || entry.Name == "" {
+		return entry, errors.New("Repository name and url are required")
+	}
+	entry.Group = strings.TrimSpace(entry.Group)
+	entry.Mention = strings.TrimSpace(entry.Mention)
+	entry.Provider = strings.ToLower(strings.TrimSpace(entry.Provider))
+	if entry.Provider == "" {
+		entry.Provider = DefaultProvider
+	}
+	entry.VersionConstraint = strings.TrimSpace(entry.VersionConstraint)
+	if err := ValidateVersionConstraint(entry.VersionConstraint); err != nil {
+		return entry, err
+	}
+	if entry.CooldownDays != nil && *entry.CooldownDays < 0 {
+		entry.CooldownDays = nil
+	}
+	tags := []string{}
+	for _, tag := range entry.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	entry.Tags = tags
+
+	ignores := []WatchlistIgnore{}
+	for _, ignore := range entry.Ignores {
+		ignore.Type = strings.ToLower(strings.TrimSpace(ignore.Type))
+		ignore.Value = strings.TrimSpace(ignore.Value)
+		ignore.Reason = strings.TrimSpace(ignore.Reason)
+		if ignore.Until != nil {
+			until := ignore.Until.UTC()
+			ignore.Until = &until
+		}
+		if ignore.Type == IgnoreTypeSnooze && ignore.Until != nil && !ignore.Until.After(time.Now()) {
+			continue
+		}
+		if err := ValidateIgnore(models.RepositoryIgnore{Type: ignore.Type, Value: ignore.Value, Until: ignore.Until}); err != nil {
+			return entry, err
+		}
+		ignores = append(ignores, ignore)
+	}
+	entry.Ignores = ignores
+	return entry, nil
+}
+
+func createImportedRepository(db *gorm.DB, entry WatchlistRepository, githubToken string) (models.Repository, error) {
+	repo := models.Repository{CurrentVersion: entry.CurrentVersion}
+	applyWatchlistRepository(&repo, entry)
+	if err := AddRepository(db, &repo, githubToken); err != nil {
+		return repo, err
+	}
+	return repo, replaceIgnores(db, repo.ID, entry.Ignores)
+}
+
+func updateImportedRepository(db *gorm.DB, repo *models.Repository, entry WatchlistRepository, githubToken string) error {
+	constraintChanged := repo.VersionConstraint != entry.VersionConstraint
+	applyWatchlistRepository(repo, entry)
+	if constraintChanged {
+		tracked, upstream, found, err := FetchTrackedRelease(*repo, githubToken)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrNoMatchingRelease
+		}
+		BaselineRelease(repo, tracked, upstream)
+		if err := CancelPendingNotifications(db, repo.ID); err != nil {
+			utils.Logger.Warn("Failed to cancel pending notifications: ", err)
+		}
+	}
+	repo.CurrentVersion = entry.CurrentVersion
+	if err := db.Save(repo).Error; err != nil {
+		return err
+	}
+	return replaceIgnores(db, repo.ID, entry.Ignores)
+}
+
+func applyWatchlistRepository(repo *models.Repository, entry WatchlistRepository) {
+	repo.Name = entry.Name
+	repo.URL = entry.URL
+	repo.Group = entry.Group
+	repo.Tags = entry.Tags
+	repo.Provider = entry.Provider
+	repo.Mention = entry.Mention
+	repo.CooldownDays = entry.CooldownDays
+	repo.VersionConstraint = entry.VersionConstraint
+}
+
+func replaceIgnores(db *gorm.DB, repositoryID uint, ignores []WatchlistIgnore) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("repository_id = ?", repositoryID).Delete(&models.RepositoryIgnore{}).Error; err != nil {
+			return err
+		}
+		for _, ignore := range ignores {
+			record := models.RepositoryIgnore{RepositoryID: repositoryID, Type: ignore.Type, Value: ignore.Value, Until: ignore.Until, Reason: ignore.Reason}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func importChannels(db *gorm.DB, entries []WatchlistChannel, options ImportOptions, report *ImportReport) error {
+	var channels []models.NotificationChannel
+	if err := db.Find(&channels).Error; err != nil {
+		return err
+	}
+	existing := map[string]models.NotificationChannel{}
+	for _, channel := range channels {
+		existing[channelKey(channel.Name, channel.Type)] = channel
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry.Name = strings.TrimSpace(entry.Name)
+		entry.Type = strings.ToLower(strings.TrimSpace(entry.Type))
+		result := ImportResult{Name: entry.Name, Type: entry.Type}
+		key := channelKey(entry.Name, entry.Type)
+		channel, exists := existing[key]
+		if seen[key] {
+			result.Action, result.Error = ImportActionConflict, "Channel is listed more than once"
+			report.Channels = append(report.Channels, result)
+			continue
+		}
+		seen[key] = true
+
+		var current WatchlistChannel
+		if exists {
+			result.ID = channel.ID
+			var err error
+			if current, err = watchlistChannel(channel); err != nil {
+				result.Action, result.Error = ImportActionFailed, err.Error()
+				report.Channels = append(report.Channels, result)
+				continue
+			}
+		}
+		config, err := validateWatchlistChannel(&entry, current.Config)
+		switch {
+		case err != nil:
+			result.Action, result.Error = ImportActionFailed, err.Error()
+		case exists:
+			result.Changes = diffFields(redactWatchlistChannel(current), redactWatchlistChannel(entry))
+			if _, ok := result.Changes["config"]; !ok && !reflect.DeepEqual(jsonFields(current.Config), jsonFields(entry.Config)) {
+				result.Changes["config"] = ImportChange{From: RedactedSecret, To: RedactedSecret}
+			}
+			switch {
+			case len(result.Changes) == 0:
+				result.Action = ImportActionUnchanged
+			case options.SkipConflicts:
+				result.Action, result.Error = ImportActionConflict, "A channel with this name and type already exists"
+			default:
+				result.Action = ImportActionUpdate
+				if !options.DryRun {
+					if err := saveImportedChannel(db, &channel, entry, config); err != nil {
+						result.Action, result.Error = ImportActionFailed, err.Error()
+					}
+				}
+			}
+		default:
+			result.Action = ImportActionCreate
+			if !options.DryRun {
+				channel = models.NotificationChannel{}
+				if err := saveImportedChannel(db, &channel, entry, config); err != nil {
+					result.Action, result.Error = ImportActionFailed, err.Error()
+				}
+				result.ID = channel.ID
+			}
+		}
+		report.Channels = append(report.Channels, result)
+	}
+
+	if options.Mode != ImportModeReplace {
+		return nil
+	}
+	for _, channel := range channels {
+		if seen[channelKey(channel.Name, channel.Type)] {
+			continue
+		}
+		result := ImportResult{Name: channel.Name, Type: channel.Type, ID: channel.ID, Action: ImportActionDelete}
+		if !options.DryRun && !options.Confirm {
+			result.Action = ImportActionKeep
+		} else if !options.DryRun {
+			if err := DeleteNotificationChannel(db, channel); err != nil {
+				result.Action, result.Error = ImportActionFailed, err.Error()
+			} else {
+				UnscheduleDigest(channel.ID)
+			}
+		}
+		report.Channels = append(report.Channels, result)
+	}
+	return nil
+}
+
+// validateWatchlistChannel fills in defaults and redacted secrets from the
+// existing config, then checks the channel the same way the channel API does.
+func validateWatchlistChannel(entry *WatchlistChannel, existingConfig map[string]interface{}) ([]byte, error) {
+	if entry.Name == "" || entry.Type == "" {
+		return nil, errors.New("Channel type and name are required")
+	}
+	if entry.Enabled == nil {
+		enabled := true
+		entry.Enabled = &enabled
+	}
+	if entry.Config == nil {
+		entry.Config = map[string]interface{}{}
+	}
+	if err := restoreRedacted(entry.Config, existingConfig); err != nil {
+		return nil, err
+	}
+	entry.DeliveryMode = strings.ToLower(strings.TrimSpace(entry.DeliveryMode))
+	if entry.DeliveryMode == "" {
+		entry.DeliveryMode = DeliveryModeImmediate
+	}
+	entry.DigestSchedule = strings.TrimSpace(entry.DigestSchedule)
+	if len(entry.QuietHours.Days) == 0 {
+		entry.QuietHours.Days = nil
+	}
+
+	config, err := json.Marshal(entry.Config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := BuildNotifier(entry.Type, config, entry.Templates); err != nil {
+		return nil, err
+	}
+	if err := ValidateDeliveryMode(entry.DeliveryMode, entry.DigestSchedule); err != nil {
+		return nil, err
+	}
+	if err := ValidateQuietHours(entry.QuietHours); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func saveImportedChannel(db *gorm.DB, channel *models.NotificationChannel, entry WatchlistChannel, config []byte) error {
+	encrypted, err := EncryptChannelConfig(config)
+	if err != nil {
+		return err
+	}
+	channel.Name = entry.Name
+	channel.Type = entry.Type
+	channel.Enabled = *entry.Enabled
+	channel.Config = encrypted
+	channel.Templates = entry.Templates
+	channel.DeliveryMode = entry.DeliveryMode
+	channel.DigestSchedule = entry.DigestSchedule
+	channel.QuietHours = entry.QuietHours
+	channel.OperationalAlerts = entry.OperationalAlerts
+	if err := db.Save(channel).Error; err != nil {
+		return err
+	}
+	if err := ScheduleDigest(db, *channel); err != nil {
+		utils.Logger.Error("Error scheduling digest: ", err)
+	}
+	if err := ReleaseHeldNotifications(db, channel.ID); err != nil {
+		utils.Logger.Error("Error releasing held notifications: ", err)
+	}
+	return nil
+}
+
+func watchlistRepository(repo models.Repository, ignores []models.RepositoryIgnore) WatchlistRepository {
+	exported := WatchlistRepository{
+		Name:              repo.Name,
+		URL:               repo.URL,
+		CurrentVersion:    repo.CurrentVersion,
+		Group:             repo.Group,
+		Tags:              repo.Tags,
+		Provider:          repo.Provider,
+		Mention:           repo.Mention,
+		CooldownDays:      repo.CooldownDays,
+		VersionConstraint: repo.VersionConstraint,
+		Ignores:           []WatchlistIgnore{},
+	}
+	if exported.Tags == nil {
+		exported.Tags = []string{}
+	}
+	for _, ignore := range ignores {
+		if ignore.Type == IgnoreTypeSnooze && ignore.Until != nil && !ignore.Until.After(time.Now()) {
+			continue
+		}
+		var until *time.Time
+		if ignore.Until != nil {
+			utc := ignore.Until.UTC()
+			until = &utc
+		}
+		exported.Ignores = append(exported.Ignores, WatchlistIgnore{Type: ignore.Type, Value: ignore.Value, Until: until, Reason: ignore.Reason})
+	}
+	return exported
+}
+
+// watchlistChannel converts channel for export. When its config cannot be
+// decrypted the channel is still returned, with an empty config.
+func watchlistChannel(channel models.NotificationChannel) (WatchlistChannel, error) {
+	enabled := channel.Enabled
+	if len(channel.QuietHours.Days) == 0 {
+		channel.QuietHours.Days = nil
+	}
+	exported := WatchlistChannel{
+		Name:              channel.Name,
+		Type:              channel.Type,
+		Enabled:           &enabled,
+		Config:            map[string]interface{}{},
+		Templates:         channel.Templates,
+		DeliveryMode:      channel.DeliveryMode,
+		DigestSchedule:    channel.DigestSchedule,
+		QuietHours:        channel.QuietHours,
+		OperationalAlerts: channel.OperationalAlerts,
+	}
+	raw, err := DecryptChannelConfig(channel)
+	if err != nil {
+		return exported, err
+	}
+	return exported, json.Unmarshal(raw, &exported.Config)
+}
+
+func redactWatchlistChannel(channel WatchlistChannel) WatchlistChannel {
+	channel.Config = redactConfig(channel.Config)
+	return channel
+}
+
+// restoreRedacted puts the existing value back for every secret given as
+// RedactedSecret.
+func restoreRedacted(config, existingConfig map[string]interface{}) error {
+	for key, value := range config {
+		if value != RedactedSecret {
+			continue
+		}
+		existing, ok := existingConfig[key]
+		if !ok {
+			return fmt.Errorf("%s is redacted; provide the secret to save this channel", key)
+		}
+		config[key] = existing
+	}
+	return nil
+}
+
+func redactConfig(config map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		if secretConfigKeys[key] && value != nil && !reflect.ValueOf(value).IsZero() {
+			value = RedactedSecret
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// diffFields compares two values field by field through their JSON form and
+// returns the fields that differ.
+func diffFields(from, to interface{}) map[string]ImportChange {
+	before, after := jsonFields(from), jsonFields(to)
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	changes := map[string]ImportChange{}
+	for key := range keys {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			changes[key] = ImportChange{From: before[key], To: after[key]}
+		}
+	}
+	return changes
+}
+
+func jsonFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	raw, err := json.Marshal(value)
+	if err == nil {
+		json.Unmarshal(raw, &fields)
+	}
+	return fields
+}
+
+func ignoresByRepository(db *gorm.DB) (map[uint][]models.RepositoryIgnore, error) {
+	var ignores []models.RepositoryIgnore
+	if err := db.Order("id").Find(&ignores).Error; err != nil {
+		return nil, err
+	}
+	byRepository := map[uint][]models.RepositoryIgnore{}
+	for _, ignore := range ignores {
+		byRepository[ignore.RepositoryID] = append(byRepository[ignore.RepositoryID], ignore)
+	}
+	return byRepository, nil
+}
+
+func repositoryKey(url string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(url), "/"))
+}
+
+func channelKey(name, channelType string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + strings.ToLower(channelType)
+}
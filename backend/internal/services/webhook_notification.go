@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const (
+	EventReleaseDetected = "release.detected"
+	EventAlertRaised     = "alert.raised"
+	EventAlertResolved   = "alert.resolved"
+	SignatureHeader      = "X-Surveillance-Signature"
+)
+
+// ReleaseEvent is the JSON body POSTed by webhook channels, one request per
+// detected release:
+//
+//	{
+//	  "event": "release.detected",
+//	  "repository": "facebook/react",
+//	  "url": "https://github.com/facebook/react",
+//	  "releaseUrl": "https://github.com/facebook/react/releases/tag/v19.0.0",
+//	  "previousVersion": "v18.3.1",
+//	  "newVersion": "v19.0.0",
+//	  "changelog": "...",
+//	  "severity": "major",
+//	  "security": false,
+//	  "scanType": "Scheduled",
+//	  "timestamp": "2025-01-01T12:00:00Z",
+//	  "mention": "@here"
+//	}
+//
+// mention is only present when a routing rule attached one.
+//
+// A custom bodyTemplate is executed with this struct as its data. Every
+// request carries an X-Surveillance-Signature header of the form
+// "sha256=<hex>", the HMAC-SHA256 of the raw request body keyed with the
+// channel secret.
+type ReleaseEvent struct {
+	Event           string    `json:"event"`
+	Repository      string    `json:"repository"`
+	URL             string    `json:"url"`
+	ReleaseURL      string    `json:"releaseUrl"`
+	PreviousVersion string    `json:"previousVersion"`
+	NewVersion      string    `json:"newVersion"`
+	Changelog       string    `json:"changelog"`
+	Severity        string    `json:"severity"`
+	Security        bool      `json:"security"`
+	ScanType        string    `json:"scanType"`
+	Timestamp       time.Time `json:"timestamp"`
+	Mention         string    `json:"mention,omitempty"`
+}
+
+// AlertEvent is POSTed for operational alerts with the event "alert.raised"
+// or "alert.resolved". It is signed like ReleaseEvent but never passed
+// through bodyTemplate.
+type AlertEvent struct {
+	Event     string    `json:"event"`
+	Kind      string    `json:"kind"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type WebhookConfig struct {
+	URL          string            `json:"url"`
+	Secret       string            `json:"secret"`
+	Headers      map[string]string `json:"headers"`
+	BodyTemplate string            `json:"bodyTemplate"`
+}
+
+type WebhookNotifier struct {
+	httpSender
+	config   WebhookConfig
+	template *template.Template
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
+
+func newWebhookNotifier(raw []byte, _ messageTemplates) (Notifier, error) {
+	var config WebhookConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	if config.URL == "" {
+		return nil, errors.New("webhook URL is not set")
+	}
+	if config.Secret == "" {
+		return nil, errors.New("webhook signing secret is not set")
+	}
+	notifier := &WebhookNotifier{httpSender: newHTTPSender(), config: config}
+	if config.BodyTemplate != "" {
+		tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(config.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook body template: %w", err)
+		}
+		notifier.template = tmpl
+	}
+	return notifier, nil
+}
+
+func (w *WebhookNotifier) Send(notification Notification) error {
+	if alert := notification.Alert; alert != nil {
+		event := AlertEvent{Event: EventAlertRaised, Kind: alert.Kind, Title: alert.Title, Message: alert.Message, Timestamp: notification.Time.UTC()}
+		if alert.Resolved {
+			event.Event = EventAlertResolved
+		}
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return w.post(event.Event, body)
+	}
+
+	for _, update := range notification.Updates {
+		event := ReleaseEvent{
+			Event:           EventReleaseDetected,
+			Repository:      update.Repository,
+			URL:             update.URL,
+			ReleaseURL:      update.ReleaseURL(),
+			PreviousVersion: update.PreviousVersion,
+			NewVersion:      update.NewVersion,
+			Changelog:       update.Changelog,
+			Severity:        update.Severity,
+			Security:        update.Security,
+			ScanType:        notification.ScanType,
+			Timestamp:       notification.Time.UTC(),
+			Mention:         notification.Mention,
+		}
+		body, err := w.renderBody(event)
+		if err != nil {
+			return err
+		}
+		if err := w.post(EventReleaseDetected, body); err != nil {
+			return fmt.Errorf("%s: %w", update.Repository, err)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookNotifier) post(event string, body []byte) error {
+	contentType := "application/json"
+	headers := map[string]string{}
+	for key, value := range w.config.Headers {
+		key = http.CanonicalHeaderKey(key)
+		if key == "Content-Type" {
+			contentType = value
+			continue
+		}
+		headers[key] = value
+	}
+	headers["X-Surveillance-Event"] = event
+	headers[SignatureHeader] = SignPayload(w.config.Secret, body)
+	return w.send("POST", w.config.URL, contentType, body, headers)
+}
+
+func (w *WebhookNotifier) renderBody(event ReleaseEvent) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(event)
+	}
+	var body bytes.Buffer
+	if err := w.template.Execute(&body, event); err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
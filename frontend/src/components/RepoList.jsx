@@ -14,14 +14,8 @@ const RepoList = ({
 
   const sortedRepos = useMemo(() => {
     return [...repos].sort((a, b) => {
-      const aUpdate =
-        a.CurrentVersion &&
-        a.LatestRelease &&
-        a.CurrentVersion !== a.LatestRelease;
-      const bUpdate =
-        b.CurrentVersion &&
-        b.LatestRelease &&
-        b.CurrentVersion !== b.LatestRelease;
+      const aUpdate = Boolean(a.updateAvailable);
+      const bUpdate = Boolean(b.updateAvailable);
       if (aUpdate === bUpdate) return 0;
       return aUpdate ? -1 : 1;
     });
@@ -9,7 +9,12 @@ import {
 import InputField from "@/components/ui/InputField";
 import AvatarPreview from "@/components/ui/AvatarPreview";
 
+// The API returns stored secrets as this placeholder and keeps the stored
+// value when it is sent back unchanged.
+const REDACTED_SECRET = "<redacted>";
+
 const validateWebhookUrl = (url) =>
+  url === REDACTED_SECRET ||
   /^https:\/\/discord\.com\/api\/webhooks\/\d+\/[\w-]+$/.test(url);
 const validateAvatarUrl = (url) =>
   url === "" || /\.(jpg|jpeg|png|gif|webp)$/.test(url);
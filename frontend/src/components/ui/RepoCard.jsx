@@ -16,10 +16,7 @@ const RepoCard = ({
 
   const toggleExpand = () => setExpanded((prev) => !prev);
 
-  const updateAvailable =
-    repo.CurrentVersion &&
-    repo.LatestRelease &&
-    repo.CurrentVersion !== repo.LatestRelease;
+  const updateAvailable = Boolean(repo.updateAvailable);
 
   const displayName =
     repo.Name && repo.Name.includes("/")
@@ -59,6 +59,14 @@ const RepoDetails = ({ repo }) => {
             </span>
           )}
         </div>
+        {repo.VersionConstraint &&
+          repo.UpstreamLatest &&
+          repo.UpstreamLatest !== repo.LatestRelease && (
+            <div className="mt-1 text-xs text-gray-400">
+              Within {repo.VersionConstraint} · newest overall{" "}
+              {repo.UpstreamLatest}
+            </div>
+          )}
       </div>
       <div className="bg-[var(--color-details-bg)] border border-[var(--color-border)] rounded-lg p-4 text-center transition-all duration-200">
         <span className="block text-xs uppercase tracking-wider text-gray-400">
@@ -68,6 +76,18 @@ const RepoDetails = ({ repo }) => {
           {repo.LastUpdated ? timeAgo(repo.LastUpdated) : "Not Available"}
         </div>
       </div>
+      {repo.ignoredBy && (
+        <div className="md:col-span-3 bg-[var(--color-details-bg)] border border-[var(--color-border)] rounded-lg p-4 text-sm">
+          <span className="block text-xs uppercase tracking-wider text-gray-400">
+            {repo.ignoredBy.type === "snooze"
+              ? `Snoozed until ${new Date(repo.ignoredBy.until).toLocaleDateString()}`
+              : `Ignoring ${repo.ignoredBy.value}`}
+          </span>
+          <div className="mt-1 font-medium">
+            {repo.ignoredBy.reason || "No reason given"}
+          </div>
+        </div>
+      )}
     </div>
   );
 };
@@ -63,11 +63,16 @@ export const validateApiKey = async (apiKey) => {
   const result = await apiRequest("post", "/api/validate-key", { apiKey });
   return result?.message === "GitHub API key is valid";
 };
-export const fetchNotifications = () => apiRequest("get", "/api/notifications");
-export const updateNotifications = (settings) =>
-  apiRequest("post", "/api/notifications", settings);
-export const testNotificationAPI = () =>
-  apiRequest("post", "/api/notifications/test");
+export const fetchNotificationChannels = () =>
+  apiRequest("get", "/api/notification-channels");
+export const createNotificationChannel = (channel) =>
+  apiRequest("post", "/api/notification-channels", channel);
+export const updateNotificationChannel = (id, channel) =>
+  apiRequest("put", `/api/notification-channels/${id}`, channel);
+export const deleteNotificationChannel = (id) =>
+  apiRequest("delete", `/api/notification-channels/${id}`);
+export const testNotificationChannel = (id) =>
+  apiRequest("post", `/api/notification-channels/${id}/test`);
 export const markRepositoryUpdatedAPI = (id) =>
   apiRequest("post", `/api/repositories/${id}/mark-updated`);
 export const loginUser = (credentials) =>
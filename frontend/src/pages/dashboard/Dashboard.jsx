@@ -94,7 +94,11 @@ const Dashboard = () => {
       setRepos((prev) =>
         prev.map((repo) =>
           repo.ID === id
-            ? { ...repo, CurrentVersion: updatedRepo.CurrentVersion }
+            ? {
+                ...repo,
+                CurrentVersion: updatedRepo.CurrentVersion,
+                updateAvailable: updatedRepo.updateAvailable,
+              }
             : repo,
         ),
       );
@@ -2,15 +2,17 @@ import { useState, useEffect } from "react";
 import { FaBell } from "react-icons/fa";
 import Toast from "@/components/ui/Toast";
 import {
-  fetchNotifications,
-  updateNotifications,
-  testNotificationAPI,
+  fetchNotificationChannels,
+  createNotificationChannel,
+  updateNotificationChannel,
+  testNotificationChannel,
 } from "@/config/api";
 import NotificationForm from "@/components/modals/NotificationForm";
 
 const Notifications = () => {
   const [toast, setToast] = useState(null);
   const [loading, setLoading] = useState(true);
+  const [channel, setChannel] = useState(null);
   const [initialSettings, setInitialSettings] = useState({
     webhookUrl: "",
     discordName: "",
@@ -22,8 +24,12 @@ const Notifications = () => {
     const loadSettings = async () => {
       setLoading(true);
       try {
-        const data = await fetchNotifications();
-        setInitialSettings(data);
+        const channels = await fetchNotificationChannels();
+        const discord = channels?.find((c) => c.type === "discord");
+        if (discord) {
+          setChannel(discord);
+          setInitialSettings(discord.config);
+        }
       } catch (error) {
         showToast("error", "Failed to load notification settings.");
       } finally {
@@ -40,7 +46,16 @@ const Notifications = () => {
 
   const handleSave = async (formData) => {
     try {
-      await updateNotifications(formData);
+      const payload = {
+        type: "discord",
+        name: channel?.name || "Discord",
+        enabled: channel ? channel.enabled : true,
+        config: formData,
+      };
+      const saved = channel
+        ? await updateNotificationChannel(channel.ID, payload)
+        : await createNotificationChannel(payload);
+      setChannel(saved);
       showToast("success", "Notification settings saved successfully!");
       setInitialSettings(formData);
     } catch (error) {
@@ -53,8 +68,12 @@ const Notifications = () => {
       showToast("error", message);
       return;
     }
+    if (!channel) {
+      showToast("error", "Save the notification settings first.");
+      return;
+    }
     try {
-      const res = await testNotificationAPI();
+      const res = await testNotificationChannel(channel.ID);
       showToast(
         "success",
         res.message || "Test notification sent successfully!",